@@ -0,0 +1,269 @@
+package closer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Aggregator combines the errors of the functions closed by a single Close
+// into the error it returns (prefixed with the name of the method).
+// With WithMaxErrors, the errors dropped over the cap are represented by
+// a last error saying "and N more errors".
+type Aggregator interface {
+	// Aggregate combines errs, which is never empty.
+	Aggregate(errs []error) error
+}
+
+// AggregatorFunc is an adapter to use an ordinary function as an Aggregator.
+type AggregatorFunc func(errs []error) error
+
+// Aggregate calls a(errs).
+func (a AggregatorFunc) Aggregate(errs []error) error {
+	return a(errs)
+}
+
+// JoinErrors returns the Aggregator joining the messages of all the errors
+// with sep. JoinErrors("; ") is the default one.
+func JoinErrors(sep string) Aggregator {
+	return AggregatorFunc(func(errs []error) error {
+		return &joinError{msg: join(errs, sep), errs: errs}
+	})
+}
+
+// FirstError returns the Aggregator keeping only the first error.
+func FirstError() Aggregator {
+	return AggregatorFunc(func(errs []error) error {
+		return errs[0]
+	})
+}
+
+// CapErrors returns the Aggregator joining the messages of the first n
+// errors with "; " and counting the rest, e.g. "...; and 1324 more errors",
+// so hundreds of failures don't produce a megabyte-long message.
+func CapErrors(n int) Aggregator {
+	n = max(n, 1)
+
+	return AggregatorFunc(func(errs []error) error {
+		if len(errs) <= n {
+			return &joinError{msg: join(errs, ";\x20"), errs: errs}
+		}
+
+		more := 0
+
+		// The errors dropped by WithMaxErrors are counted as well
+		for _, err := range errs[n:] {
+			if dropped, ok := err.(moreErrors); ok {
+				more += int(dropped)
+			} else {
+				more++
+			}
+		}
+
+		msg := fmt.Sprintf("%s;\x20and %d more errors", join(errs[:n], ";\x20"), more)
+
+		// The message is capped, the members are not
+		return &joinError{msg: msg, errs: errs}
+	})
+}
+
+// joinError is an aggregated error: errors.Is and errors.As look through
+// every member, not just the first one.
+type joinError struct {
+	msg  string
+	errs []error
+}
+
+func (e *joinError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the members of the error.
+func (e *joinError) Unwrap() []error {
+	return e.errs
+}
+
+// join joins the messages of the errors with sep.
+func join(errs []error, sep string) string {
+	msgs := make([]string, len(errs))
+
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, sep)
+}
+
+// AggregateError is the error of Close (and its variants, App.Run and
+// OpenAll) when functions fail: the errors combined by the Aggregator,
+// prefixed with the name of the method. errors.Is and errors.As look
+// through every error of the functions, whatever the Aggregator, and
+// through the combined error. Formatted with %+v, it lists the failed
+// functions one per line with their category and duration, and the stack
+// traces of the panics.
+type AggregateError struct {
+	Op      string  // Method that failed, e.g. "closer.Close"
+	Errs    []error // Errors of the functions, mostly FuncError
+	Dropped int     // Number of the errors dropped over the cap, see WithMaxErrors
+	err     error   // Combined error, see Aggregator
+}
+
+func (e *AggregateError) Error() string {
+	return e.Op + ":\x20" + e.err.Error()
+}
+
+// Unwrap returns the errors of the functions and the error combined by
+// the Aggregator.
+func (e *AggregateError) Unwrap() []error {
+	return append(slices.Clip(e.Errs), e.err)
+}
+
+// Failed returns the errors of all the functions that failed, whatever
+// their category, including the ones of nested aggregate errors, e.g.
+// of the scopes or of Close within App.Run.
+func (e *AggregateError) Failed() []FuncError {
+	return funcErrors(e.Errs, func(FuncError) bool { return true })
+}
+
+// TimedOut returns the errors of the functions that exceeded a deadline,
+// see CategoryTimedOut.
+func (e *AggregateError) TimedOut() []FuncError {
+	return funcErrors(e.Errs, func(fErr FuncError) bool {
+		return fErr.Category == CategoryTimedOut
+	})
+}
+
+// Critical returns the errors of the critical functions that failed,
+// see WithCritical.
+func (e *AggregateError) Critical() []FuncError {
+	return funcErrors(e.Errs, func(fErr FuncError) bool {
+		return fErr.Critical
+	})
+}
+
+// Format formats the error like Error for %v and %s; %+v lists the failed
+// functions one per line, with the stack traces of the panics.
+func (e *AggregateError) Format(f fmt.State, verb rune) {
+	if verb != 'v' || !f.Flag('+') {
+		_, _ = io.WriteString(f, e.Error())
+		return
+	}
+
+	failed := e.Failed()
+
+	fmt.Fprintf(f, "%s: %d functions failed", e.Op, len(failed)+e.Dropped)
+
+	for _, fErr := range failed {
+		fmt.Fprintf(f, "\n\t%s (%s, %v): %v", fErr.Name, fErr.Category, fErr.Duration, fErr.Err)
+
+		if len(fErr.Stack) > 0 {
+			_, _ = io.WriteString(f, "\n\t\t"+strings.ReplaceAll(strings.TrimSpace(string(fErr.Stack)), "\n", "\n\t\t"))
+		}
+	}
+
+	if e.Dropped > 0 {
+		fmt.Fprintf(f, "\n\t%v", moreErrors(e.Dropped))
+	}
+}
+
+// funcErrors returns the function errors of errs matching keep, looking
+// into the nested aggregate errors.
+func funcErrors(errs []error, keep func(FuncError) bool) []FuncError {
+	var fErrs []FuncError
+
+	for _, err := range errs {
+		fErr, ok := err.(FuncError)
+
+		if !ok {
+			var agg *AggregateError
+
+			if errors.As(err, &agg) {
+				fErrs = append(fErrs, funcErrors(agg.Errs, keep)...)
+				continue
+			}
+
+			if !errors.As(err, &fErr) {
+				continue
+			}
+		}
+
+		if keep(fErr) {
+			fErrs = append(fErrs, fErr)
+		}
+	}
+
+	return fErrs
+}
+
+// moreErrors stands for the errors dropped over the cap, see WithMaxErrors.
+type moreErrors int
+
+func (n moreErrors) Error() string {
+	return fmt.Sprintf("and %d more errors", int(n))
+}
+
+// aggregate returns the AggregateError of the errors of op, keeping the
+// first ones up to the cap (see WithMaxErrors) and counting the others
+// with the dropped ones. The fatal errors, e.g. of the critical functions,
+// are never dropped nor counted against the cap, so ExitCode stays right.
+func (c *Closer) aggregate(op string, errs []error, dropped int) *AggregateError {
+	if c.maxErrors > 0 && len(errs) > c.maxErrors {
+		var (
+			kept  = make([]error, 0, c.maxErrors) // Copied, so the dropped errors are not referenced anymore
+			count atomic.Int32
+		)
+
+		for _, err := range errs {
+			if c.retain(&count, err) {
+				kept = append(kept, err)
+			} else {
+				dropped++
+			}
+		}
+
+		errs = kept
+	}
+
+	combined := errs
+
+	if dropped > 0 {
+		combined = append(slices.Clip(errs), moreErrors(dropped))
+	}
+
+	return &AggregateError{Op: op, Errs: errs, Dropped: dropped, err: c.getAggregator().Aggregate(combined)}
+}
+
+// errDropped is the error of the results of the functions whose errors
+// were dropped over the cap, see WithMaxErrors.
+var errDropped = errors.New(ErrDropped)
+
+// retain reports whether an error of a function is kept: the fatal ones
+// and the first others up to the cap (see WithMaxErrors), counted with
+// kept, are; the rest is dropped.
+func (c *Closer) retain(kept *atomic.Int32, err error) bool {
+	return c.maxErrors == 0 || fatal(err) || int(kept.Add(1)) <= c.maxErrors
+}
+
+// keepError returns the error of a function for the error of the Close,
+// nil if it's dropped over the cap, counted with the dropped ones instead.
+func (c *Closer) keepError(run *closeRun, err error) error {
+	if err == nil || c.retain(&run.kept, err) {
+		return err
+	}
+
+	run.dropped.Add(1)
+
+	return nil
+}
+
+// getAggregator returns the configured aggregator or the default one.
+func (c *Closer) getAggregator() Aggregator {
+	if c.aggregator == nil {
+		return JoinErrors(";\x20")
+	}
+
+	return c.aggregator
+}
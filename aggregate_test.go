@@ -0,0 +1,219 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Aggregator_HappyPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		aggregator Aggregator
+		expected   string
+	}{
+		{name: "default", expected: "closer.Close: error 0; error 1; error 2; error 3"},
+		{name: "join", aggregator: JoinErrors(", "), expected: "closer.Close: error 0, error 1, error 2, error 3"},
+		{name: "first", aggregator: FirstError(), expected: "closer.Close: error 0"},
+		{name: "cap", aggregator: CapErrors(2), expected: "closer.Close: error 0; error 1; and 2 more errors"},
+		{name: "cap_not_reached", aggregator: CapErrors(4), expected: "closer.Close: error 0; error 1; error 2; error 3"},
+		{
+			name: "custom",
+			aggregator: AggregatorFunc(func(errs []error) error {
+				return fmt.Errorf("%d errors", len(errs))
+			}),
+			expected: "closer.Close: 4 errors",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := []Option{WithSynchronousExecution()}
+
+			if test.aggregator != nil {
+				opts = append(opts, WithAggregator(test.aggregator))
+			}
+
+			cl := New(opts...)
+
+			for i := range 4 {
+				cl.Add(func(ctx context.Context) error {
+					return fmt.Errorf("error %d", i)
+				})
+			}
+
+			require.EqualError(t, cl.Close(context.Background()), test.expected)
+		})
+	}
+}
+
+func Test_Aggregator_NoErrorsPath(t *testing.T) {
+	cl := New(WithAggregator(AggregatorFunc(func(errs []error) error {
+		return errors.New("must not be called")
+	})))
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.Close(context.Background()))
+}
+
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+func Test_Aggregator_IsAsPath(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	for _, aggregator := range []Aggregator{JoinErrors(", "), CapErrors(1), FirstError()} {
+		cl := New(WithAggregator(aggregator), WithSynchronousExecution())
+
+		cl.Add(func(ctx context.Context) error { return errFailed })
+		cl.Add(func(ctx context.Context) error { return &codeError{code: 42} }, WithName("db"))
+		cl.Add(func(ctx context.Context) error {
+			return fmt.Errorf("flush: %w", context.DeadlineExceeded)
+		})
+
+		err := cl.Close(context.Background())
+
+		// Every error is looked through, the capped and the dropped ones as well
+		require.ErrorIs(t, err, errFailed)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var codeErr *codeError
+
+		require.ErrorAs(t, err, &codeErr)
+		require.Equal(t, 42, codeErr.code)
+	}
+}
+
+func Test_AggregateError_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	cl.Add(func(ctx context.Context) error { return errors.New("boom") }, WithName("db"))
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("cache"))
+	cl.Add(func(ctx context.Context) error {
+		return fmt.Errorf("flush: %w", context.DeadlineExceeded)
+	}, WithName("queue"))
+
+	err := cl.Close(context.Background())
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, err, &agg)
+	require.Equal(t, "closer.Close", agg.Op)
+	require.EqualError(t, err, "closer.Close: db: boom; queue: flush: context deadline exceeded")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	failed := agg.Failed()
+	require.Len(t, failed, 2)
+	require.Equal(t, "db", failed[0].Name)
+	require.Equal(t, CategoryFailed, failed[0].Category)
+
+	timedOut := agg.TimedOut()
+	require.Len(t, timedOut, 1)
+	require.Equal(t, "queue", timedOut[0].Name)
+
+	require.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+
+	lines := fmt.Sprintf("%+v", err)
+	require.Contains(t, lines, "closer.Close: 2 functions failed\n\tdb (failed, ")
+	require.Contains(t, lines, "\n\tqueue (timed out, ")
+}
+
+func Test_AggregateError_NestedPath(t *testing.T) {
+	app := NewApp(0)
+	app.Closer().Add(func(ctx context.Context) error { return errors.New("boom") }, WithName("db"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, app.Run(ctx), &agg)
+	require.Equal(t, "closer.App.Run", agg.Op)
+
+	// The failures of Close within Run are collected too
+	failed := agg.Failed()
+	require.Len(t, failed, 1)
+	require.Equal(t, "db", failed[0].Name)
+}
+
+func Test_MaxErrors_HappyPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		aggregator Aggregator
+		expected   string
+	}{
+		{name: "join", aggregator: JoinErrors("; "), expected: "closer.Close: error 0; error 1; and 3 more errors"},
+		{name: "cap", aggregator: CapErrors(1), expected: "closer.Close: error 0; and 4 more errors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := New(WithMaxErrors(2), WithAggregator(tt.aggregator), WithSynchronousExecution())
+
+			for i := range 5 {
+				cl.Add(func(ctx context.Context) error { return fmt.Errorf("error %d", i) })
+			}
+
+			err := cl.Close(context.Background())
+
+			require.EqualError(t, err, tt.expected)
+
+			var agg *AggregateError
+
+			require.ErrorAs(t, err, &agg)
+			require.Len(t, agg.Errs, 2)
+			require.Equal(t, 3, agg.Dropped)
+			require.Contains(t, fmt.Sprintf("%+v", err), "closer.Close: 5 functions failed")
+			require.Contains(t, fmt.Sprintf("%+v", err), "\n\tand 3 more errors")
+
+			// The results don't retain the errors over the cap either
+			for i, res := range cl.Results() {
+				require.Equal(t, StatusFailed, res.Status)
+
+				if i < 2 {
+					require.EqualError(t, res.Err, fmt.Sprintf("error %d", i))
+				} else {
+					require.EqualError(t, res.Err, ErrDropped)
+				}
+			}
+		})
+	}
+}
+
+func Test_MaxErrors_ConcurrentPath(t *testing.T) {
+	cl := New(WithMaxErrors(3))
+
+	for i := range 100 {
+		cl.Add(func(ctx context.Context) error { return fmt.Errorf("error %d", i) })
+	}
+
+	cl.Add(func(ctx context.Context) error { return errors.New("critical") }, WithCritical())
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, cl.Close(context.Background()), &agg)
+
+	// The critical error is kept over the cap, the others are counted only
+	require.Len(t, agg.Errs, 4)
+	require.Equal(t, 97, agg.Dropped)
+	require.Len(t, agg.Critical(), 1)
+
+	dropped := 0
+
+	for _, res := range cl.Results() {
+		if res.Err != nil && res.Err.Error() == ErrDropped {
+			dropped++
+		}
+	}
+
+	require.Equal(t, 97, dropped)
+}
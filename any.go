@@ -0,0 +1,64 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// AddAny adds the function releasing v, so third-party clients are
+// registered without adapters. The method is looked up in the order:
+// Shutdown(ctx) error, Close(ctx) error, Close() error, Stop(ctx) error,
+// Stop(ctx), Stop() error, Stop(). AddAny panics, naming the call site of
+// the registration, if v is nil or has none of them; otherwise it works
+// like Add.
+func (c *Closer) AddAny(v any, opts ...FuncOption) Handle {
+	op := "closer.AddAny"
+
+	f, ok := funcOf(v)
+
+	if !ok && v != nil {
+		panic(fmt.Sprintf("%s: %v in %T registered at %s", op, ErrNoCloseMethod, v, callSite(1)))
+	}
+
+	return mustAdd(c.add(op, f, opts))
+}
+
+// funcOf returns the function calling the close method of v, if any.
+func funcOf(v any) (Func, bool) {
+	switch v := v.(type) {
+	case interface{ Shutdown(context.Context) error }:
+		return v.Shutdown, true
+	case interface{ Close(context.Context) error }:
+		return v.Close, true
+	case interface{ Close() error }:
+		return func(context.Context) error { return v.Close() }, true
+	case interface{ Stop(context.Context) error }:
+		return v.Stop, true
+	case interface{ Stop(context.Context) }:
+		return func(ctx context.Context) error { v.Stop(ctx); return nil }, true
+	case interface{ Stop() error }:
+		return func(context.Context) error { return v.Stop() }, true
+	case interface{ Stop() }:
+		return func(context.Context) error { v.Stop(); return nil }, true
+	default:
+		return nil, false
+	}
+}
+
+// AddCloserT adds the function closing v with Close() and returns v, so
+// the registration composes with a constructor call and keeps the static
+// type: f := closer.AddCloserT(cl, must(os.Open(name))).
+func AddCloserT[T io.Closer](c *Closer, v T, opts ...FuncOption) T {
+	mustAdd(c.add("closer.AddCloserT", func(context.Context) error { return v.Close() }, opts))
+
+	return v
+}
+
+// AddStopperT adds the function stopping v with Stop() and returns v,
+// see AddCloserT. Any closers.Stopper fits, e.g. a time.Ticker.
+func AddStopperT[T interface{ Stop() }](c *Closer, v T, opts ...FuncOption) T {
+	mustAdd(c.add("closer.AddStopperT", func(context.Context) error { v.Stop(); return nil }, opts))
+
+	return v
+}
@@ -0,0 +1,87 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type shutdowner struct{ calls *[]string }
+
+func (s shutdowner) Shutdown(ctx context.Context) error {
+	*s.calls = append(*s.calls, "shutdown")
+	return nil
+}
+
+func (s shutdowner) Close() error {
+	*s.calls = append(*s.calls, "close")
+	return nil
+}
+
+type ioCloser struct{ calls *[]string }
+
+func (c ioCloser) Close() error {
+	*c.calls = append(*c.calls, "close")
+	return errors.New("failed")
+}
+
+type ctxStopper struct{ calls *[]string }
+
+func (s ctxStopper) Stop(ctx context.Context) {
+	*s.calls = append(*s.calls, "stop ctx")
+}
+
+type stopper struct{ calls *[]string }
+
+func (s stopper) Stop() {
+	*s.calls = append(*s.calls, "stop")
+}
+
+func Test_AddAny_HappyPath(t *testing.T) {
+	var calls []string
+
+	cl := New(WithSynchronousExecution())
+
+	// Shutdown takes precedence over Close
+	cl.AddAny(shutdowner{calls: &calls})
+	cl.AddAny(ioCloser{calls: &calls}, WithName("file"))
+	cl.AddAny(ctxStopper{calls: &calls})
+	cl.AddAny(stopper{calls: &calls})
+
+	require.EqualError(t, cl.Close(context.Background()), "closer.Close: file: failed")
+	require.Equal(t, []string{"shutdown", "close", "stop ctx", "stop"}, calls)
+}
+
+func Test_AddAny_ErrorPath(t *testing.T) {
+	var cl Closer
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.AddAny: %v in int registered at %s", ErrNoCloseMethod, lineAfter(t, 1)), func() {
+		cl.AddAny(42)
+	})
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.AddAny: %v registered at %s", ErrNilFunc, lineAfter(t, 1)), func() {
+		cl.AddAny(nil)
+	})
+
+	require.Zero(t, cl.Size())
+}
+
+func Test_AddCloserT_HappyPath(t *testing.T) {
+	var calls []string
+
+	cl := New(WithSynchronousExecution())
+
+	c := AddCloserT(cl, ioCloser{calls: &calls}, WithName("file"))
+	s := AddStopperT(cl, &stopper{calls: &calls})
+
+	// The static types are kept
+	require.IsType(t, ioCloser{}, c)
+	require.IsType(t, &stopper{}, s)
+	require.Equal(t, 2, cl.Size())
+
+	require.EqualError(t, cl.Close(context.Background()), "closer.Close: file: failed")
+	require.Equal(t, []string{"close", "stop"}, calls)
+}
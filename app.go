@@ -0,0 +1,142 @@
+package closer
+
+import (
+	"context"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// App bundles the runners and the closers of a service: it starts the
+// runners, waits for a termination signal or a runner error, then closes
+// everything within a budget. It is the main function everyone writes
+// by hand.
+type App struct {
+	cl      *Closer
+	timeout time.Duration
+
+	mu     sync.Mutex
+	starts []Func
+}
+
+// NewApp returns an App closing the registered functions with a Closer
+// configured with opts, within timeout (no limit if 0).
+func NewApp(timeout time.Duration, opts ...Option) *App {
+	return &App{cl: New(opts...), timeout: timeout}
+}
+
+// Closer returns the Closer of the App, e.g. to add the functions that
+// have nothing to start or to use stages.
+func (a *App) Closer() *Closer {
+	return a.cl
+}
+
+// Register registers a runner of the App: start runs it until its context
+// is done, stop is added to the Closer. Either of them can be nil.
+// A start returning an error before the shutdown stops the App; a start
+// returning nil just finishes.
+func (a *App) Register(start, stop Func) {
+	if stop != nil {
+		mustAdd(a.cl.add("closer.App.Register", stop, nil))
+	}
+
+	if start != nil {
+		a.mu.Lock()
+		a.starts = append(a.starts, start)
+		a.mu.Unlock()
+	}
+}
+
+// Run runs all the starters and waits for SIGINT, SIGTERM, the end of ctx
+// or the first starter error. Then it cancels the context of the starters,
+// closes the Closer within the timeout of the App and waits for the
+// starters to return, as long as the timeout allows. The errors returned
+// by the starters after the shutdown began are ignored.
+// Run returns the starter error and the error of Close, if any.
+func (a *App) Run(ctx context.Context) error {
+	op := "closer.App.Run"
+
+	sigCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	runCtx, cancel := context.WithCancel(sigCtx)
+	defer cancel()
+
+	a.mu.Lock()
+	starts := a.starts
+	a.mu.Unlock()
+
+	var (
+		wg       sync.WaitGroup
+		failMu   sync.Mutex
+		startErr error // First starter error before the shutdown
+	)
+
+	for _, start := range starts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := start(runCtx)
+
+			failMu.Lock()
+			defer failMu.Unlock()
+
+			if err != nil && runCtx.Err() == nil {
+				startErr = err
+				cancel()
+			}
+		}()
+	}
+
+	<-runCtx.Done()
+
+	// Restore the default signal behavior so a second signal
+	// terminates the process while closing
+	stop()
+
+	var errs []error
+
+	failMu.Lock()
+
+	if startErr != nil {
+		errs = append(errs, startErr)
+	}
+
+	failMu.Unlock()
+
+	closeCtx := context.WithoutCancel(ctx)
+
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+
+		closeCtx, cancel = withTimeout(closeCtx, a.cl.getClock(), a.timeout)
+		defer cancel()
+	}
+
+	if a.cl.Remaining() > 0 {
+		if err := a.cl.Close(closeCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-closeCtx.Done():
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return a.cl.aggregate(op, errs, 0)
+}
@@ -0,0 +1,95 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_App_HappyPath(t *testing.T) {
+	var (
+		started atomic.Int32
+		stopped atomic.Int32
+	)
+
+	app := NewApp(time.Second)
+
+	for range 3 {
+		app.Register(func(ctx context.Context) error {
+			started.Add(1)
+			<-ctx.Done()
+
+			// Errors after the shutdown began are ignored
+			return errors.New("server closed")
+		}, func(ctx context.Context) error {
+			stopped.Add(1)
+			return nil
+		})
+	}
+
+	// A runner that just finishes doesn't stop the App
+	app.Register(func(ctx context.Context) error { return nil }, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		for started.Load() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+
+		cancel()
+	}()
+
+	require.NoError(t, app.Run(ctx))
+	require.Equal(t, int32(3), stopped.Load())
+}
+
+func Test_App_StartErrorPath(t *testing.T) {
+	var stopped atomic.Bool
+
+	app := NewApp(time.Second)
+
+	app.Register(func(ctx context.Context) error {
+		return errors.New("listen failed")
+	}, nil)
+
+	app.Register(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}, func(ctx context.Context) error {
+		stopped.Store(true)
+		return errors.New("flush failed")
+	})
+
+	err := app.Run(context.Background())
+
+	require.EqualError(t, err, "closer.App.Run: listen failed; closer.Close: flush failed")
+	require.True(t, stopped.Load())
+}
+
+func Test_App_CancelWithCtxPath(t *testing.T) {
+	app := NewApp(10 * time.Millisecond)
+	release := make(chan struct{})
+
+	t.Cleanup(func() { close(release) })
+
+	app.Register(func(ctx context.Context) error {
+		// Ignores the shutdown, the App doesn't wait past its timeout
+		<-release
+		return nil
+	}, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := app.Run(ctx)
+
+	require.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
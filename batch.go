@@ -0,0 +1,90 @@
+package closer
+
+import "sync"
+
+// Batch stages registrations to be added to a Closer all at once, see
+// Closer.Batch.
+type Batch struct {
+	c *Closer
+
+	mu      sync.Mutex
+	entries []entry // Staged entries
+}
+
+// Batch returns a builder staging the registrations until Commit adds all
+// of them atomically or Discard drops them, so a constructor failing
+// halfway doesn't leave partial cleanups in a shared Closer:
+//
+//	b := cl.Batch()
+//	defer b.Discard() // No-op after Commit
+//
+//	b.Add(db.Close)
+//	if err := initCache(); err != nil {
+//		return err
+//	}
+//	b.Add(cache.Close)
+//
+//	b.Commit()
+func (c *Closer) Batch() *Batch {
+	return &Batch{c: c}
+}
+
+// Add stages a function like Closer.Add, it panics if f is nil.
+func (b *Batch) Add(f Func, opts ...FuncOption) {
+	b.add("closer.Batch.Add", f, opts)
+}
+
+// AddStage stages a function in the given stage like Closer.AddStage,
+// it panics if f is nil.
+func (b *Batch) AddStage(stage int, f Func, opts ...FuncOption) {
+	b.add("closer.Batch.AddStage", f, append([]FuncOption{WithStage(stage)}, opts...))
+}
+
+// add stages f, op is the exported method called by the user.
+func (b *Batch) add(op string, f Func, opts []FuncOption) {
+	e := newEntry(op, f, opts)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = append(b.entries, e)
+}
+
+// Commit adds the staged functions to the Closer at once, in the staging
+// order, and returns their handles. Close never sees only a part of them.
+// The batch is emptied and can be reused. In the strict mode Commit panics
+// once the shutdown has begun, adding nothing.
+func (b *Batch) Commit() []Handle {
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.mu.Unlock()
+
+	c := b.c
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range entries {
+		if err := c.checkLate("closer.Batch.Commit", e); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	handles := make([]Handle, len(entries))
+
+	for i, e := range entries {
+		handles[i] = c.insert(e)
+	}
+
+	return handles
+}
+
+// Discard drops the staged functions without running them.
+// The batch is emptied and can be reused.
+func (b *Batch) Discard() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = nil
+}
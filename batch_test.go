@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Batch_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	mocks := []*mockCloseFunc{{}, {}, {}}
+
+	cl.Add(mocks[0].close, WithName("log"))
+
+	b := cl.Batch()
+	defer b.Discard()
+
+	b.AddStage(1, mocks[1].close, WithName("db"))
+	b.Add(mocks[2].close, WithName("cache"))
+
+	// Nothing is registered until Commit
+	require.Equal(t, 1, cl.Size())
+
+	handles := b.Commit()
+
+	require.Len(t, handles, 2)
+	require.Equal(t, []string{"log", "cache", "db"}, cl.Plan())
+
+	require.NoError(t, handles[0].Close(context.Background()))
+	require.Equal(t, 1, mocks[1].calledCount)
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []int{1, 1, 1}, []int{mocks[0].calledCount, mocks[1].calledCount, mocks[2].calledCount})
+}
+
+func Test_Batch_DiscardPath(t *testing.T) {
+	cl := New()
+	mock := &mockCloseFunc{}
+
+	b := cl.Batch()
+	b.Add(mock.close)
+	b.Discard()
+
+	require.Empty(t, b.Commit())
+	require.Equal(t, 0, cl.Size())
+	require.Equal(t, 0, mock.calledCount)
+}
+
+func Test_Batch_NilPath(t *testing.T) {
+	b := New().Batch()
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.Batch.Add: %v registered at %s", ErrNilFunc, lineAfter(t, 1)), func() {
+		b.Add(nil)
+	})
+}
@@ -0,0 +1,216 @@
+package closer
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// stage is a group of functions closed concurrently.
+type stage struct {
+	id      int
+	entries []entry
+}
+
+// splitStages groups the entries by stage in ascending order.
+// Within a stage the entries are ordered by descending priority,
+// the registration order is kept for the same priority. The entries are
+// sorted in place and the stages share them, so nothing is copied.
+func splitStages(entries []entry) []stage {
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if a.stage != b.stage {
+			return cmp.Compare(a.stage, b.stage)
+		}
+
+		return b.priority - a.priority
+	})
+
+	var stages []stage
+
+	for i := 0; i < len(entries); {
+		j := i + 1
+
+		for j < len(entries) && entries[j].stage == entries[i].stage {
+			j++
+		}
+
+		stages = append(stages, stage{id: entries[i].stage, entries: entries[i:j:j]})
+		i = j
+	}
+
+	return stages
+}
+
+// splitPriorities splits every stage into groups of the functions with
+// the same priority, in descending order, closed one after another.
+// The groups keep the id of their stage.
+func splitPriorities(stages []stage) []stage {
+	var groups []stage
+
+	for _, st := range stages {
+		for i := 0; i < len(st.entries); {
+			j := i + 1
+
+			for j < len(st.entries) && st.entries[j].priority == st.entries[i].priority {
+				j++
+			}
+
+			groups = append(groups, stage{id: st.id, entries: st.entries[i:j]})
+			i = j
+		}
+	}
+
+	return groups
+}
+
+// stageContext derives the context for the first of the remaining stages.
+// In the budget mode the stage gets its share of the time left until the
+// deadline of ctx, otherwise ctx is returned as is.
+func (c *Closer) stageContext(ctx context.Context, stages []stage) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+
+	// The last stage gets all the remaining time anyway
+	if !c.budget || !ok || len(stages) < 2 {
+		return ctx, func() {}
+	}
+
+	total := 0
+
+	for _, s := range stages {
+		total += c.stageWeight(s.id)
+	}
+
+	if total <= 0 {
+		return ctx, func() {}
+	}
+
+	clock := c.getClock()
+	share := float64(deadline.Sub(clock.Now())) * float64(c.stageWeight(stages[0].id)) / float64(total)
+
+	return withTimeout(ctx, clock, time.Duration(share))
+}
+
+// stageWeight returns the budget weight of the stage.
+func (c *Closer) stageWeight(id int) int {
+	if w, ok := c.weights[id]; ok {
+		return max(w, 0)
+	}
+
+	return 1
+}
+
+// funcContext derives the context for a function of the given weight run
+// sequentially, left is the weight of the functions still to run including
+// this one. In the function budget mode the function gets its share of
+// the time left until the deadline of ctx, otherwise ctx is returned as is.
+func (c *Closer) funcContext(ctx context.Context, weight, left int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+
+	// The last function gets all the remaining time anyway
+	if !c.funcBudget || !ok || weight >= left {
+		return ctx, func() {}
+	}
+
+	clock := c.getClock()
+	share := float64(deadline.Sub(clock.Now())) * float64(weight) / float64(left)
+
+	return withTimeout(ctx, clock, time.Duration(share))
+}
+
+// budgetWeights returns the budget weights of the entries: their weights
+// (see WithWeight) scaled by their baselines in milliseconds if there is
+// a duration store (see WithDurationStore). The entries without a baseline
+// get the mean baseline of the others.
+func (c *Closer) budgetWeights(entries []entry) []int {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	weights := make([]int, len(entries))
+
+	for i, e := range entries {
+		weights[i] = e.weight
+	}
+
+	if c.store == nil {
+		return weights
+	}
+
+	var (
+		baselines = make([]int, len(entries))
+		sum, n    int
+	)
+
+	for i, e := range entries {
+		if d, ok := c.baseline(e); ok {
+			baselines[i] = max(int(d/time.Millisecond), 1)
+			sum += baselines[i]
+			n++
+		}
+	}
+
+	if n == 0 {
+		return weights
+	}
+
+	for i := range weights {
+		if baselines[i] == 0 {
+			baselines[i] = max(sum/n, 1)
+		}
+
+		weights[i] *= baselines[i]
+	}
+
+	return weights
+}
+
+// weightAt returns the weight of the i-th function, 1 if weights is nil.
+func weightAt(weights []int, i int) int {
+	if weights == nil {
+		return 1
+	}
+
+	return weights[i]
+}
+
+// pendingWeights returns the budget weight of the taken entry e and the
+// one of e together with the functions not taken yet.
+// It must be called under the mutex.
+func (c *Closer) pendingWeights(e entry) (weight, left int) {
+	entries := []entry{e}
+
+	for i := c.i; i < len(c.funcs); i++ {
+		if !c.funcs[i].taken {
+			entries = append(entries, c.funcs[i])
+		}
+	}
+
+	weights := c.budgetWeights(entries)
+
+	for _, w := range weights {
+		left += w
+	}
+
+	return weights[0], left
+}
+
+// fallbackContext replaces ctx with a fresh context bounded by the
+// fallback timeout if ctx is already done, see WithFallbackTimeout.
+// The values of ctx are kept.
+func (c *Closer) fallbackContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.fallback <= 0 || ctx.Err() == nil {
+		return ctx, func() {}
+	}
+
+	return withTimeout(context.WithoutCancel(ctx), c.getClock(), c.fallback)
+}
+
+// closeContext bounds ctx by the close timeout, see WithCloseTimeout.
+func (c *Closer) closeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.closeTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return withTimeout(ctx, c.getClock(), c.closeTimeout)
+}
@@ -0,0 +1,229 @@
+package closer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddStage_OrderPath(t *testing.T) {
+	var (
+		cl    Closer
+		mu    sync.Mutex
+		order []int
+	)
+
+	record := func(stage int) Func {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			order = append(order, stage)
+
+			return nil
+		}
+	}
+
+	cl.AddStage(2, record(2))
+	cl.AddStage(-1, record(-1))
+	cl.Add(record(0))
+	cl.AddStage(2, record(2))
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []int{-1, 0, 2, 2}, order)
+}
+
+func Test_StageBudget_HappyPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		share float64
+	}{
+		{name: "equal", opts: []Option{WithStageBudget()}, share: 1.0 / 3},
+		{name: "weighted", opts: []Option{WithStageWeight(0, 2)}, share: 2.0 / 4},
+		{name: "disabled", share: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := New(test.opts...)
+
+			var deadline time.Time
+
+			cl.AddStage(0, func(ctx context.Context) error {
+				deadline, _ = ctx.Deadline()
+				return nil
+			})
+			cl.AddStage(1, func(ctx context.Context) error { return nil })
+			cl.AddStage(2, func(ctx context.Context) error { return nil })
+
+			timeout := 3 * time.Second
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			start := time.Now()
+
+			require.NoError(t, cl.Close(ctx))
+
+			expected := time.Duration(float64(timeout) * test.share)
+			require.InDelta(t, expected, deadline.Sub(start), float64(100*time.Millisecond))
+		})
+	}
+}
+
+func Test_StageBudget_UnusedTimePath(t *testing.T) {
+	cl := New(WithStageBudget())
+
+	var deadline time.Time
+
+	cl.AddStage(0, func(ctx context.Context) error { return nil })
+	cl.AddStage(1, func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	})
+	cl.AddStage(2, func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.Close(ctx))
+
+	// The first stage returns at once, so the second one gets half of the whole budget
+	require.InDelta(t, time.Second, deadline.Sub(start), float64(100*time.Millisecond))
+}
+
+func Test_FuncBudget_SynchronousPath(t *testing.T) {
+	cl := New(WithSynchronousExecution(), WithFuncBudget())
+
+	deadlines := make([]time.Time, 3)
+
+	for i := range deadlines {
+		cl.Add(func(ctx context.Context) error {
+			deadlines[i], _ = ctx.Deadline()
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.Close(ctx))
+
+	// The unused time is passed on to the following functions
+	require.InDelta(t, time.Second, deadlines[0].Sub(start), float64(100*time.Millisecond))
+	require.InDelta(t, 1500*time.Millisecond, deadlines[1].Sub(start), float64(100*time.Millisecond))
+	require.InDelta(t, 3*time.Second, deadlines[2].Sub(start), float64(100*time.Millisecond))
+}
+
+func Test_FuncBudget_CloseOnePath(t *testing.T) {
+	cl := New(WithFuncBudget())
+
+	var deadline time.Time
+
+	cl.Add(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	})
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.CloseOne(ctx))
+	require.InDelta(t, time.Second, deadline.Sub(start), float64(100*time.Millisecond))
+}
+
+func Test_FuncBudget_WeightPath(t *testing.T) {
+	cl := New(WithSynchronousExecution(), WithFuncBudget())
+
+	deadlines := make([]time.Time, 2)
+
+	cl.Add(func(ctx context.Context) error {
+		deadlines[0], _ = ctx.Deadline()
+		return nil
+	}, WithWeight(3))
+	cl.Add(func(ctx context.Context) error {
+		deadlines[1], _ = ctx.Deadline()
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.Close(ctx))
+
+	// The heavier function gets three quarters of the budget
+	require.InDelta(t, 3*time.Second, deadlines[0].Sub(start), float64(100*time.Millisecond))
+	require.InDelta(t, 4*time.Second, deadlines[1].Sub(start), float64(100*time.Millisecond))
+}
+
+func Test_FuncBudget_WeightCloseOnePath(t *testing.T) {
+	cl := New(WithFuncBudget())
+
+	var deadline time.Time
+
+	cl.Add(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	})
+	cl.Add(func(ctx context.Context) error { return nil }, WithWeight(3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.CloseOne(ctx))
+	require.InDelta(t, time.Second, deadline.Sub(start), float64(100*time.Millisecond))
+}
+
+func Test_FallbackTimeout_HappyPath(t *testing.T) {
+	cl := New(WithFallbackTimeout(time.Second), WithSkipOnCancel())
+	mocks := []*mockCloseFunc{{}, {}}
+
+	var deadline time.Time
+
+	cl.Add(mocks[0].close)
+	cl.Add(mocks[1].close)
+	cl.Add(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.Close(ctx))
+
+	for _, mcf := range mocks {
+		require.Equal(t, 1, mcf.calledCount)
+	}
+
+	require.InDelta(t, time.Second, deadline.Sub(start), float64(100*time.Millisecond))
+}
+
+func Test_FallbackTimeout_CloseOnePath(t *testing.T) {
+	cl := New(WithFallbackTimeout(time.Second))
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, cl.CloseOne(ctx))
+	require.Equal(t, 1, mcf.calledCount)
+}
@@ -0,0 +1,82 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	ErrChaos = "chaos: synthetic error"
+)
+
+// Chaos configures the chaos mode, see WithChaos.
+type Chaos struct {
+	Seed      uint64        // Seed of the random source, the same seed gives the same decisions
+	MaxDelay  time.Duration // Upper bound of the random delay before each function
+	ErrorRate float64       // Probability of a synthetic error, from 0 to 1
+	Shuffle   bool          // Whether the functions of a stage are started in a random order
+}
+
+// chaos injects the imperfections described by Chaos into the functions.
+// It must be used under the Closer mutex.
+type chaos struct {
+	Chaos
+	src *rand.PCG
+	rnd *rand.Rand
+}
+
+func newChaos(cfg Chaos) *chaos {
+	src := rand.NewPCG(cfg.Seed, cfg.Seed)
+
+	return &chaos{
+		Chaos: cfg,
+		src:   src,
+		rnd:   rand.New(src),
+	}
+}
+
+// clone returns an independent copy of the chaos going on from the same
+// state of the random source.
+func (ch *chaos) clone() *chaos {
+	src := *ch.src
+
+	return &chaos{
+		Chaos: ch.Chaos,
+		src:   &src,
+		rnd:   rand.New(&src),
+	}
+}
+
+// shuffle shuffles the functions of a stage, if enabled.
+func (ch *chaos) shuffle(n int, swap func(i, j int)) {
+	if ch.Shuffle {
+		ch.rnd.Shuffle(n, swap)
+	}
+}
+
+// wrap decides upfront on the delay and the synthetic error for f,
+// so the decisions depend only on the seed and not on the scheduling.
+func (ch *chaos) wrap(f Func, clock Clock) Func {
+	var delay time.Duration
+
+	if ch.MaxDelay > 0 {
+		delay = time.Duration(ch.rnd.Int64N(int64(ch.MaxDelay)))
+	}
+
+	fail := ch.ErrorRate > 0 && ch.rnd.Float64() < ch.ErrorRate
+
+	return func(ctx context.Context) error {
+		sleep(ctx, clock, delay)
+
+		// The function runs anyway, so the resource is still released
+		err := f(ctx)
+
+		if err == nil && fail {
+			err = errors.New(ErrChaos)
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,82 @@
+package closer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Chaos_ErrorRatePath(t *testing.T) {
+	tests := []struct {
+		rate  float64
+		count int
+	}{
+		{rate: 0, count: 0},
+		{rate: 1, count: 10},
+	}
+
+	for _, test := range tests {
+		cl := New(WithChaos(Chaos{Seed: 1, ErrorRate: test.rate}))
+		mocks := make([]*mockCloseFunc, 10)
+
+		for i := range mocks {
+			mocks[i] = &mockCloseFunc{}
+			cl.Add(mocks[i].close)
+		}
+
+		err := cl.Close(context.Background())
+
+		for _, mcf := range mocks {
+			require.Equal(t, 1, mcf.calledCount)
+		}
+
+		if test.count == 0 {
+			require.NoError(t, err)
+		} else {
+			require.Equal(t, test.count, strings.Count(err.Error(), ErrChaos))
+		}
+	}
+}
+
+func Test_Chaos_ShufflePath(t *testing.T) {
+	run := func(seed uint64) []int {
+		cl := New(WithSynchronousExecution(), WithChaos(Chaos{Seed: seed, Shuffle: true}))
+
+		var order []int
+
+		for i := range 10 {
+			cl.Add(func(ctx context.Context) error {
+				order = append(order, i)
+				return nil
+			})
+		}
+
+		require.NoError(t, cl.Close(context.Background()))
+
+		return order
+	}
+
+	first := run(42)
+
+	require.Len(t, first, 10)
+	require.Equal(t, first, run(42))
+	require.NotEqual(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, first)
+}
+
+func Test_Chaos_DelayPath(t *testing.T) {
+	cl := New(WithChaos(Chaos{Seed: 1, MaxDelay: time.Hour}))
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := cl.CloseOne(ctx)
+
+	require.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	require.Equal(t, 1, mcf.calledCount)
+}
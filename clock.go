@@ -0,0 +1,128 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Clock provides the time to the timeout logic of a Closer.
+// Tests can supply a fake clock (see closertest.Clock) to verify the
+// timeout behavior without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls f in its own goroutine after the duration elapses.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled by Clock.AfterFunc.
+type Timer interface {
+	// Stop prevents the call from firing.
+	// It reports whether the call was stopped before it fired.
+	Stop() bool
+}
+
+// realClock is the Clock backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// getClock returns the configured clock or the real one.
+func (c *Closer) getClock() Clock {
+	if c.clock == nil {
+		return realClock{}
+	}
+
+	return c.clock
+}
+
+// clockContext is a context whose deadline is driven by a Clock. Its done
+// channel is its own, so the contexts derived from it wait for it and take
+// its error rather than the one of the cancelled context it wraps:
+// context.DeadlineExceeded once the deadline is exceeded.
+type clockContext struct {
+	context.Context // Cancelled, with DeadlineExceeded as the cause at the deadline
+	deadline        time.Time
+	done            chan struct{} // Closed once the wrapped context is cancelled
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockContext) Err() error {
+	select {
+	case <-c.done:
+	default:
+		return nil
+	}
+
+	if errors.Is(context.Cause(c.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+
+	return c.Context.Err()
+}
+
+// withTimeout is context.WithTimeout measuring the time with clock.
+func withTimeout(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+
+	deadline := clock.Now().Add(d)
+
+	// The parent deadline is sooner, the new one would never fire
+	if cur, ok := ctx.Deadline(); ok && cur.Before(deadline) {
+		return context.WithCancel(ctx)
+	}
+
+	inner, cancel := context.WithCancelCause(ctx)
+	tctx := &clockContext{Context: inner, deadline: deadline, done: make(chan struct{})}
+
+	context.AfterFunc(inner, func() {
+		close(tctx.done)
+	})
+
+	if d <= 0 {
+		cancel(context.DeadlineExceeded)
+
+		return tctx, func() { cancel(context.Canceled) }
+	}
+
+	timer := clock.AfterFunc(d, func() {
+		cancel(context.DeadlineExceeded)
+	})
+
+	return tctx, func() {
+		timer.Stop()
+		cancel(context.Canceled)
+	}
+}
+
+// sleep waits for d measured with clock or until ctx is done.
+func sleep(ctx context.Context, clock Clock, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	timer := clock.AfterFunc(d, func() { close(done) })
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		timer.Stop()
+	}
+}
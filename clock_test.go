@@ -0,0 +1,85 @@
+package closer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// manualClock is a minimal fake clock firing its timers on demand.
+type manualClock struct {
+	now    time.Time
+	timers chan func()
+}
+
+func (m *manualClock) Now() time.Time {
+	return m.now
+}
+
+func (m *manualClock) AfterFunc(d time.Duration, f func()) Timer {
+	m.timers <- f
+	return manualTimer{}
+}
+
+type manualTimer struct{}
+
+func (manualTimer) Stop() bool {
+	return true
+}
+
+func Test_WithTimeout_FakeClockPath(t *testing.T) {
+	clock := &manualClock{now: time.Now(), timers: make(chan func(), 1)}
+
+	ctx, cancel := withTimeout(context.Background(), clock, time.Minute)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, clock.now.Add(time.Minute), deadline)
+	require.NoError(t, ctx.Err())
+
+	// Fire the timer
+	(<-clock.timers)()
+
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func Test_WithTimeout_CancelPath(t *testing.T) {
+	clock := &manualClock{now: time.Now(), timers: make(chan func(), 1)}
+
+	ctx, cancel := withTimeout(context.Background(), clock, time.Minute)
+	cancel()
+
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func Test_WithTimeout_DerivedPath(t *testing.T) {
+	clock := &manualClock{now: time.Now(), timers: make(chan func(), 1)}
+
+	ctx, cancel := withTimeout(context.Background(), clock, time.Minute)
+	defer cancel()
+
+	derived, cancelDerived := context.WithCancel(ctx)
+	defer cancelDerived()
+
+	stopped := make(chan error, 1)
+
+	context.AfterFunc(ctx, func() {
+		stopped <- ctx.Err()
+	})
+
+	// Fire the timer
+	(<-clock.timers)()
+
+	<-derived.Done()
+
+	// The derived contexts see the deadline too
+	require.Equal(t, context.DeadlineExceeded, derived.Err())
+	require.Equal(t, context.DeadlineExceeded, context.Cause(derived))
+	require.Equal(t, context.DeadlineExceeded, context.Cause(ctx))
+	require.Equal(t, context.DeadlineExceeded, <-stopped)
+}
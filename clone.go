@@ -0,0 +1,88 @@
+package closer
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns an independent Closer with the same options and the
+// functions not closed yet, including the disabled ones and the pairs
+// waiting for OpenAll. The functions are shared, not copied, so closing
+// both Closers runs them twice. The results, the events subscribers and
+// the state of the shutdown are not cloned, neither is the expvar
+// publication. It suits test scenarios and per-request closers built
+// from a template.
+func (c *Closer) Clone() *Closer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl := &Closer{
+		budget:         c.budget,
+		funcBudget:     c.funcBudget,
+		weights:        maps.Clone(c.weights),
+		sync:           c.sync,
+		clock:          c.clock,
+		maxConcurrency: c.maxConcurrency,
+		executor:       c.executor,
+		aggregator:     c.aggregator,
+		maxErrors:      c.maxErrors,
+		abandon:        c.abandon,
+		logger:         c.logger,
+		logLevel:       c.logLevel,
+		name:           c.name,
+		pprofLabels:    c.pprofLabels,
+		verbose:        c.verbose,
+		decorate:       c.decorate,
+		lateAdd:        c.lateAdd,
+		strict:         c.strict,
+		dump:           c.dump,
+		dumpWriter:     c.dumpWriter,
+		skipOnCancel:   c.skipOnCancel,
+		fallback:       c.fallback,
+		shutdownDelay:  c.shutdownDelay,
+		closeTimeout:   c.closeTimeout,
+		killAfter:      c.killAfter,
+		killCode:       c.killCode,
+		onReport:       c.onReport,
+		slowReport:     c.slowReport,
+		onError:        c.onError,
+		onFatal:        c.onFatal,
+		store:          c.store,
+		lifo:           c.lifo,
+		finalizer:      c.finalizer,
+		priorityGroups: c.priorityGroups,
+		stageNames:     maps.Clone(c.stageNames),
+		reverseGroups:  c.reverseGroups,
+		config:         c.config,
+		pairs:          slices.Clone(c.pairs),
+	}
+
+	// The random sources go on from the same state
+	if c.chaos != nil {
+		cl.chaos = c.chaos.clone()
+	}
+
+	if c.shuffle != nil {
+		src := *c.shuffle
+		cl.shuffle = &src
+	}
+
+	for i := c.i; i < len(c.funcs); i++ {
+		e := c.funcs[i]
+
+		if e.taken {
+			continue
+		}
+
+		e.index = len(cl.funcs)
+		e.tags = slices.Clone(e.tags)
+		e.out = nil // The result of a previous run stays with c
+
+		cl.funcs = append(cl.funcs, e)
+	}
+
+	cl.size.Store(int64(len(cl.funcs)))
+	cl.setFinalizer()
+
+	return cl
+}
@@ -0,0 +1,68 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Clone_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution(), WithAggregator(FirstError()))
+
+	var closed []string
+
+	add := func(name string) {
+		cl.Add(func(ctx context.Context) error {
+			closed = append(closed, name)
+			return errors.New(name)
+		}, WithName(name))
+	}
+
+	add("first")
+	require.Error(t, cl.CloseOne(context.Background()))
+
+	add("second")
+	add("third")
+	cl.Disable("third")
+
+	clone := cl.Clone()
+
+	require.Equal(t, []string{"second"}, clone.Plan())
+	require.Equal(t, 2, clone.Size())
+
+	// The clone has the options of the original and its own state
+	require.EqualError(t, clone.Close(context.Background()), "closer.Close: second: second")
+	require.Equal(t, 0, clone.Remaining())
+	require.Equal(t, 2, cl.Remaining())
+	require.Equal(t, StatusSkipped, clone.Results()[1].Status)
+
+	closed = nil
+
+	require.Error(t, cl.Close(context.Background()))
+	require.Equal(t, []string{"second"}, closed)
+}
+
+func Test_Clone_ShuffledOrderPath(t *testing.T) {
+	cl := New(WithShuffledOrder(7), WithSynchronousExecution())
+
+	var order []int
+
+	for i := range 10 {
+		cl.Add(func(ctx context.Context) error {
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	clone := cl.Clone()
+
+	require.NoError(t, cl.Close(context.Background()))
+	first := order
+
+	order = nil
+
+	require.NoError(t, clone.Close(context.Background()))
+	require.Equal(t, first, order)
+}
@@ -2,136 +2,1126 @@ package closer
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"io"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Closer manages a list of functions
 // to be closed in a controlled manner with concurrency support.
+// The zero value is ready to use; New allows to configure it with options.
+//
+// Every registered function runs at most once, whatever the interleaving
+// of Close (CloseExcept, CloseUntil, ReloadByTag), CloseOne, CloseNamed
+// and Handle.Close calls, concurrent or not: a function is claimed under
+// the mutex before it runs, and the claim is never released. Retries (see
+// WithRetry) are attempts of that single run. Close skips the functions
+// claimed by a concurrent CloseOne (CloseNamed, Handle.Close), whose errors
+// go to that caller only, but waits for them to return before it returns,
+// as long as its context allows, so the teardown is complete once Close
+// returns. A Close left with nothing to close returns at once.
 type Closer struct {
-	mu    sync.Mutex // Mutex for synchronizing access to the function
-	funcs []Func     // List of functions to close
-	size  int        // Total number of added functions
-	i     int        // Index of the current function to close
+	mu     sync.Mutex           // Mutex for synchronizing access to the function
+	funcs  []entry              // List of functions to close
+	size   atomic.Int64         // Total number of added functions, changed under the mutex only
+	taken  atomic.Int64         // Number of functions taken for closing, changed under the mutex only
+	i      int                  // Index of the first function that may be not taken yet
+	pairs  []pair               // Open/Close pairs waiting for OpenAll
+	scopes map[*Closer]struct{} // Live scopes, see Scope
+	parent *Closer              // Closer the scope belongs to, nil if not a scope
+
+	budget     bool        // Whether the ctx deadline is divided across stages
+	funcBudget bool        // Whether the ctx deadline is divided across sequential functions
+	weights    map[int]int // Budget weights of the stages
+	sync       bool        // Whether the functions are closed without goroutines
+	chaos      *chaos      // Chaos injected into the functions, if enabled
+	clock      Clock       // Clock of the timeout logic, the real one if nil
+
+	maxConcurrency int        // Maximum number of functions run at once, the default if 0, unlimited if negative
+	executor       Executor   // Executor of the workers, goroutines if nil
+	aggregator     Aggregator // Aggregator of the errors, JoinErrors if nil
+	maxErrors      int        // Maximum number of errors kept by Close and the results, unlimited if 0
+	abandon        bool       // Whether Close stops waiting for the functions once the ctx is done
+	logger         Logger     // Logger of the shutdown, none if nil
+	logLevel       Level      // Minimum level of the records logged
+	name           string     // Name of the closer
+	pprofLabels    bool       // Whether the functions run with pprof labels
+	verbose        bool       // Whether to log every function
+	decorate       Decorator  // Decorator of the function contexts, none if nil
+	lateAdd        LateAdd    // What happens to the functions added during Close
+	strict         bool       // Whether the registrations after the shutdown began are rejected
+	shuffle        *rand.PCG  // Random source of the shuffled order, none if nil
+	dump           bool       // Whether to dump the goroutines on deadline
+	dumpWriter     io.Writer  // Writer of the goroutine dump, the logger if nil
+
+	skipOnCancel  bool          // Whether the functions are skipped once the ctx is done
+	fallback      time.Duration // Timeout of the fresh context replacing a done ctx
+	shutdownDelay time.Duration // Grace period before Close runs the functions
+	closeTimeout  time.Duration // Timeout of the functions run by Close, none if 0
+	killAfter     time.Duration // Delay after the deadline before the process is killed, never if 0
+	killCode      int           // Exit code of the killed process
+	forced        chan struct{} // Closed by Force to cut the grace period short
+	forceOnce     sync.Once
+
+	onReport   func(ResourceReport) // Hook of the final resource report, none if nil
+	slowReport int                  // Number of the slowest functions logged by Close, none if 0
+	onError    func(string, error)  // Hook called as each function fails, none if nil
+	onFatal    func(string, error)  // Hook called as each critical function fails, none if nil
+	store      DurationStore        // Store of the durations of the named functions, none if nil
+	lifo       bool                 // Whether the functions are closed in the reverse order, see Deferred
+	finalizer  time.Duration        // Timeout of the finalizer fallback, none if 0
+
+	priorityGroups bool           // Whether the priorities of a stage are closed one after another
+	stageNames     map[string]int // Stages of the names, see Stage
+	reverseGroups  bool           // Whether the groups are closed in the reverse order of creation
+	config         *Config        // Tuning of the functions, see ApplyConfig
+
+	events       events        // Subscribers of the events
+	retained     atomic.Int32  // Number of the errors kept by the results, see WithMaxErrors
+	err          error         // Error of the last finished Close
+	lastDuration time.Duration // Duration of the last finished Close
+	closing      int           // Number of Close calls in progress
+	finished     bool          // Whether a Close has finished
+	shutdown     bool          // Whether a Close (but a reload) has begun
+	done         chan struct{} // Closed when the Close calls in progress finish, see WaitClosed
+	single       int           // Number of functions run by CloseOne and the like in progress
+	singleDone   chan struct{} // Closed when they return, see waitSingle
+}
+
+// New creates a Closer configured with the given options.
+func New(opts ...Option) *Closer {
+	c := &Closer{}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// NewWithCapacity creates a Closer configured with the given options,
+// with room for n functions preallocated. It avoids repeated growth
+// of the list for applications that register many functions at startup.
+func NewWithCapacity(n int, opts ...Option) *Closer {
+	c := New(opts...)
+	c.funcs = make([]entry, 0, max(n, 0))
+
+	return c
 }
 
 const (
 	ErrAllServicesClosed = "all services closed"
+	ErrReentrantClose    = "reentrant call from a close function"
+	ErrNilFunc           = "nil function"
+	ErrUnknownName       = "no function with the name"
+	ErrLateAdd           = "registration after the shutdown began"
+	ErrNoCloseMethod     = "no Shutdown, Close or Stop method"
+	ErrDropped           = "error dropped over the cap"
 )
 
+// DefaultMaxConcurrency is the number of functions of a stage closed at once
+// unless WithMaxConcurrency says otherwise. It keeps a Closer with tens of
+// thousands of functions from starting a goroutine for each of them.
+const DefaultMaxConcurrency = 1024
+
 // Add adds a function to the list for closing.
-func (c *Closer) Add(f Func) {
+// The function is placed in stage 0 unless WithStage says otherwise,
+// the other options configure how it is closed, see FuncOption.
+// Add panics if f is nil, naming the call site of the registration.
+// The returned Handle closes the function eagerly.
+// In the strict mode Add panics once the shutdown has begun, see TryAdd.
+func (c *Closer) Add(f Func, opts ...FuncOption) Handle {
+	return mustAdd(c.add("closer.Add", f, opts))
+}
+
+// TryAdd adds a function like Add, but in the strict mode (see WithStrict)
+// it returns an error naming the call site of the registration once the
+// shutdown has begun, instead of panicking. In builds with the closerdebug
+// tag it panics anyway, so lifecycle wiring bugs can't go unnoticed.
+func (c *Closer) TryAdd(f Func, opts ...FuncOption) (Handle, error) {
+	h, err := c.add("closer.TryAdd", f, opts)
+
+	if err != nil && debug {
+		panic(err.Error())
+	}
+
+	return h, err
+}
+
+// AddStage adds a function to the list for closing in the given stage.
+// Close runs the stages sequentially in ascending order,
+// while the functions of one stage are closed concurrently.
+// AddStage panics if f is nil, naming the call site of the registration.
+// The returned Handle closes the function eagerly.
+// In the strict mode AddStage panics once the shutdown has begun.
+func (c *Closer) AddStage(stage int, f Func, opts ...FuncOption) Handle {
+	return mustAdd(c.add("closer.AddStage", f, append([]FuncOption{WithStage(stage)}, opts...)))
+}
+
+// MustAdd adds a function like Add, but panics, naming the call site of
+// the registration, once the shutdown has begun whatever the mode (see
+// WithStrict), for the wiring code that must fail fast instead of leaving
+// the function for a Close that may never come.
+func (c *Closer) MustAdd(f Func, opts ...FuncOption) Handle {
+	return mustAdd(c.addEarly("closer.MustAdd", f, opts))
+}
+
+// MustAddStage adds a function to the given stage like MustAdd.
+func (c *Closer) MustAddStage(stage int, f Func, opts ...FuncOption) Handle {
+	return mustAdd(c.addEarly("closer.MustAddStage", f, append([]FuncOption{WithStage(stage)}, opts...)))
+}
+
+// addEarly registers f like add, but rejects it once the shutdown has
+// begun whatever the mode.
+func (c *Closer) addEarly(op string, f Func, opts []FuncOption) (Handle, error) {
+	e := newEntry(op, f, opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shutdown {
+		return nil, lateError(op, e)
+	}
+
+	return c.insert(e), nil
+}
+
+// add registers f, op is the exported method called by the user.
+func (c *Closer) add(op string, f Func, opts []FuncOption) (Handle, error) {
+	e := newEntry(op, f, opts)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.funcs = append(c.funcs, f)
-	c.size++
+	if err := c.checkLate(op, e); err != nil {
+		return nil, err
+	}
+
+	return c.insert(e), nil
+}
+
+// mustAdd returns the handle of a registration, it panics on the error.
+func mustAdd(h Handle, err error) Handle {
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return h
+}
+
+// checkLate returns an error in the strict mode if the shutdown has begun.
+// It must be called under the mutex.
+func (c *Closer) checkLate(op string, e entry) error {
+	if !c.strict || !c.shutdown {
+		return nil
+	}
+
+	return lateError(op, e)
+}
+
+// lateError returns the error of the entry registered after the shutdown
+// began, naming the call site of the registration.
+func lateError(op string, e entry) error {
+	return fmt.Errorf("%s: %v, registered at %s", op, ErrLateAdd, e.callSite())
+}
+
+// newEntry builds the entry of f, op is the exported method called by the
+// user, which must call newEntry through exactly one unexported method.
+// It panics if f is nil.
+func newEntry(op string, f Func, opts []FuncOption) entry {
+	if f == nil {
+		// Skip newEntry, its caller and the exported method
+		panic(fmt.Sprintf("%s: %v registered at %s", op, ErrNilFunc, callSite(3)))
+	}
+
+	e := entry{f: f, weight: 1}
+
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	// The call site is resolved lazily, see Dump
+	var pcs [1]uintptr
+
+	if runtime.Callers(4, pcs[:]) > 0 {
+		e.site = pcs[0]
+	}
+
+	return e
+}
+
+// insert appends the entry to the list and returns its handle.
+// It must be called under the mutex.
+func (c *Closer) insert(e entry) Handle {
+	e.index = len(c.funcs)
+	c.configure(&e)
+
+	c.funcs = append(c.funcs, e)
+	c.size.Add(1)
+
+	return &handle{c: c, index: e.index}
 }
 
 // Close closes all the functions in the list, starting from the current function.
+// The functions are closed stage by stage, see AddStage.
+// The list is snapshotted when Close starts: the closer is not locked
+// during the teardown, and the functions added meanwhile are left for
+// the next call to Close or CloseOne, unless WithLateAdd says otherwise.
+// The errors of the functions are combined in the order the functions
+// were started, not the order they failed in.
 func (c *Closer) Close(ctx context.Context) error {
-	op := "closer.Close"
+	err := c.close(ctx, "closer.Close", nil, false)
+
+	// A scope closed successfully detaches from its parent
+	if c.parent != nil && c.Remaining() == 0 && c.Err() == nil {
+		c.detach()
+	}
+
+	return err
+}
+
+// CloseExcept closes all the functions like Close, except the ones with
+// the given names: they stay registered and can be closed later, e.g. to
+// keep the logger alive until everything else is torn down.
+func (c *Closer) CloseExcept(ctx context.Context, names ...string) error {
+	return c.close(ctx, "closer.CloseExcept", func(e entry) bool {
+		return slices.Contains(names, e.name)
+	}, false)
+}
+
+// CloseUntil closes the functions registered after the last function with
+// the given name, which works as a marker and stays registered itself, as
+// well as the functions registered before it. The functions are closed like
+// by Close, stage by stage. It allows to roll back a partially initialized
+// subsystem.
+func (c *Closer) CloseUntil(ctx context.Context, name string) error {
+	op := "closer.CloseUntil"
+
+	marker := c.lastIndex(name)
 
+	if marker < 0 {
+		return fmt.Errorf("%s: %v %q", op, ErrUnknownName, name)
+	}
+
+	return c.close(ctx, op, func(e entry) bool {
+		return e.index <= marker
+	}, false)
+}
+
+// lastIndex returns the registration index of the last function
+// with the given name or -1 if there is none.
+func (c *Closer) lastIndex(name string) int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Check if all functions have already been closed
-	if c.i >= c.size {
-		return fmt.Errorf("%s: %v", op, ErrAllServicesClosed)
+	for i := len(c.funcs) - 1; i >= 0; i-- {
+		if c.funcs[i].name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Force cuts short the grace period of the Close calls in progress
+// and disables it for the later ones, see WithShutdownDelay.
+// It is meant to be called e.g. on a second termination signal.
+func (c *Closer) Force() {
+	c.forceOnce.Do(func() {
+		close(c.forceChan())
+	})
+}
+
+// forceChan returns the channel closed by Force.
+func (c *Closer) forceChan() chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.forced == nil {
+		c.forced = make(chan struct{})
 	}
 
-	length := c.size - c.i
+	return c.forced
+}
+
+// waitShutdownDelay waits for the grace period before closing the functions.
+// The wait is cut short if ctx is done or Force is called.
+func (c *Closer) waitShutdownDelay(ctx context.Context) {
+	if c.shutdownDelay <= 0 {
+		return
+	}
 
 	var (
-		fErrChan = make(chan error, length)  // Error channels for each function
-		fErrors  = make([]string, 0, length) // List of errors
-		wg       sync.WaitGroup              // Wait group for concurrent operations
+		done  = make(chan struct{})
+		timer = c.getClock().AfterFunc(c.shutdownDelay, func() { close(done) })
 	)
 
-	// Run each function to close it in a separate goroutine
-	for _, f := range c.funcs[c.i:] {
-		wg.Add(1)
+	defer timer.Stop()
 
-		go execF(ctx, f, &wg, fErrChan)
+	select {
+	case <-done:
+	case <-ctx.Done():
+	case <-c.forceChan():
 	}
+}
 
-	wg.Wait()
+// close closes the not yet closed functions except the ones to keep.
+// A reload is not a shutdown: it has no grace period and doesn't count
+// as a finished Close for Err and WaitClosed.
+func (c *Closer) close(ctx context.Context, op string, keep func(entry) bool, reload bool) error {
+	if c.reentrant(ctx) {
+		return fmt.Errorf("%s: %v", op, ErrReentrantClose)
+	}
 
-	// Collect all errors from the channels
+	if !reload {
+		c.mu.Lock()
+		c.shutdown = true
+		c.mu.Unlock()
 
-	for range length {
-		select {
-		case err := <-fErrChan:
-			if err != nil {
-				fErrors = append(fErrors, err.Error())
+		c.waitShutdownDelay(ctx)
+	}
+
+	stages, funcs, skipped, err := c.snapshot(keep, false)
+
+	if err != nil {
+		// Nothing was left to close: the shutdown is over all the same,
+		// the error of the Close that closed the functions, if any, is kept.
+		// The functions run by CloseOne are not waited for, this Close may
+		// be called from one of them.
+		if !reload {
+			c.mu.Lock()
+
+			c.finished = true
+
+			if c.closing == 0 && c.done != nil {
+				close(c.done)
+				c.done = nil
 			}
-		default:
+
+			c.mu.Unlock()
+		}
+
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	ctx, cancel := c.fallbackContext(c.markClosing(ctx))
+	defer cancel()
+
+	ctx, cancelTimeout := c.closeContext(ctx)
+	defer cancelTimeout()
+
+	defer c.watchDeadline(ctx, op)()
+	defer c.watchKill(ctx, op)()
+
+	stopRunning := c.watchRunning(ctx)
+
+	start := c.getClock().Now()
+
+	// Released once the error is set, for the abandoned functions
+	run := &closeRun{op: op, reload: reload, finished: make(chan struct{})}
+	defer close(run.finished)
+
+	c.emit(Event{Type: EventShutdownStarted})
+	c.emitSkipped(skipped)
+	c.log(LevelInfo, "closer: shutdown started", "op", op)
+
+	// The mutex is released, so Add, Size and CloseOne
+	// don't block for the whole teardown
+	fErrors := c.closeStages(ctx, run, stages, funcs)
+
+	// The functions added during the teardown get follow-up passes
+	for c.lateAdd == LateAddFollowUp && keep == nil && !reload {
+		stages, funcs, skipped, err := c.snapshot(nil, true)
+
+		if err != nil {
 			break
 		}
+
+		c.emitSkipped(skipped)
+
+		fErrors = append(fErrors, c.closeStages(ctx, run, stages, funcs)...)
+	}
+
+	// The durations are persisted once for the whole teardown
+	c.flushDurations()
+	c.waitSingle(ctx)
+
+	// The timeouts are reported along with the functions to blame
+	if running := stopRunning(); len(running) > 0 && slices.ContainsFunc(fErrors, isDeadline) {
+		fErrors = append(fErrors, &DeadlineError{Running: running})
+	}
+
+	if dropped := int(run.dropped.Load()); len(fErrors) > 0 || dropped > 0 {
+		err = c.aggregate(op, fErrors, dropped)
+	}
+
+	c.mu.Lock()
+
+	c.closing--
+
+	if !reload {
+		c.err = err
+		c.lastDuration = c.getClock().Now().Sub(start)
+		c.finished = true
+	}
+
+	// Wake up WaitClosed
+	if c.closing == 0 && c.finished && c.done != nil {
+		close(c.done)
+		c.done = nil
+	}
+
+	c.mu.Unlock()
+
+	if !reload {
+		c.logSlowest(ctx, op, start)
+		c.report()
 	}
 
-	// Disable further calls to CloseOne by setting the index to the size
-	c.i = c.size
+	c.emit(Event{Type: EventShutdownFinished, Err: err})
+
+	if err != nil {
+		c.log(LevelError, "closer: shutdown finished", "op", op, "duration", c.getClock().Now().Sub(start), "error", err)
+	} else {
+		c.log(LevelInfo, "closer: shutdown finished", "op", op, "duration", c.getClock().Now().Sub(start))
+	}
+
+	return err
+}
+
+// closeStages closes the stages one after another and returns the errors.
+func (c *Closer) closeStages(ctx context.Context, run *closeRun, stages []stage, funcs [][]runner) []error {
+	var fErrors []error // List of errors
+
+	for si := range stages {
+		stageCtx, cancel := c.stageContext(ctx, stages[si:])
+
+		fErrors = append(fErrors, c.closeStage(stageCtx, run, funcs[si], c.budgetWeights(stages[si].entries))...)
+
+		cancel()
+	}
+
+	return fErrors
+}
+
+// CloseFunc returns Close as a Func, so the Closer can be registered in
+// another one, e.g. to assemble an application out of the Closers owned by
+// its modules. A Closer with nothing left to close returns nil rather
+// than an ErrAllServicesClosed error.
+func (c *Closer) CloseFunc() Func {
+	return func(ctx context.Context) error {
+		if c.Remaining() == 0 {
+			return nil
+		}
 
-	if len(fErrors) > 0 {
-		return fmt.Errorf("%s: %v", op, strings.Join(fErrors, ";\x20"))
+		return c.Close(ctx)
 	}
+}
+
+// Err returns the error of the last finished Close (or CloseExcept,
+// CloseUntil), nil if it succeeded or if no Close has finished yet.
+// It lets code that only has the Closer learn how the shutdown went,
+// e.g. after Bind or WithSignals ran Close.
+func (c *Closer) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return nil
+	return c.err
+}
+
+// snapshot takes the not yet closed functions, except the ones to keep,
+// split by stages and marks them as taken, so they are owned by the caller
+// from now on. The functions to run are returned separately, prepared,
+// as well as the results of the skipped ones. A follow-up snapshot is taken
+// by a Close in progress, which is counted already.
+func (c *Closer) snapshot(keep func(entry) bool, followUp bool) ([]stage, [][]runner, []Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		pending = make([]entry, 0, len(c.funcs)-c.i)
+		outs    = make([]outcome, len(c.funcs)-c.i) // Allocated at once for all the functions
+		skipped []Result
+		taken   int
+	)
+
+	// Add only appends to the list, so the snapshot is never overwritten
+	for i := c.i; i < len(c.funcs); i++ {
+		e := &c.funcs[i]
+
+		if e.taken || (keep != nil && keep(*e)) {
+			continue
+		}
+
+		c.take(e, &outs[taken])
+		taken++
+
+		// The disabled functions are skipped
+		if e.disabled {
+			skipped = append(skipped, e.skip(ReasonDisabled))
+			continue
+		}
+
+		pending = append(pending, *e)
+	}
+
+	// The live scopes are swept by a full Close only
+	var scopes map[*Closer]struct{}
+
+	if keep == nil {
+		scopes = c.scopes
+		c.scopes = nil
+	}
+
+	// Check if all functions have already been closed
+	if taken == 0 && len(scopes) == 0 {
+		return nil, nil, nil, errors.New(ErrAllServicesClosed)
+	}
+
+	if !followUp {
+		c.closing++
+	}
+
+	// Deferred scopes and WithReverseOrder close in the reverse order
+	if c.lifo {
+		slices.Reverse(pending)
+	}
+
+	stages := splitStages(pending)
+
+	if c.priorityGroups {
+		stages = splitPriorities(stages)
+	}
+
+	var (
+		funcs = make([][]runner, len(stages))
+		tasks = make([]task, len(pending)) // Allocated at once for all the functions
+	)
+
+	for i, st := range stages {
+		if c.shuffle != nil {
+			rand.New(c.shuffle).Shuffle(len(st.entries), func(a, b int) {
+				st.entries[a], st.entries[b] = st.entries[b], st.entries[a]
+			})
+		}
+
+		if c.chaos != nil {
+			c.chaos.shuffle(len(st.entries), func(a, b int) {
+				st.entries[a], st.entries[b] = st.entries[b], st.entries[a]
+			})
+		}
+
+		funcs[i] = make([]runner, len(st.entries))
+
+		// The tasks point to the entries of the snapshot, so the entries
+		// are not copied once more
+		for j := range st.entries {
+			t := &tasks[0]
+			tasks = tasks[1:]
+
+			c.prepare(t, &st.entries[j])
+			funcs[i][j] = t
+		}
+	}
+
+	// The scopes are closed in a stage of their own before the functions
+	// of their parent
+	if len(scopes) > 0 {
+		stages = slices.Insert(stages, 0, stage{id: math.MinInt})
+		funcs = slices.Insert(funcs, 0, runners(scopeFuncs(scopes)))
+	}
+
+	return stages, funcs, skipped, nil
+}
+
+// WaitClosed blocks until no Close is in progress and at least one Close
+// has finished, then it returns the error of the last one (see Err).
+// If a Close has already finished and no other one is running, it returns
+// at once. It returns earlier with the error of ctx if ctx is done first.
+// It suits goroutines that must not exit before the teardown finishes,
+// e.g. main while a signal handler drives the shutdown.
+func (c *Closer) WaitClosed(ctx context.Context) error {
+	op := "closer.WaitClosed"
+
+	c.mu.Lock()
+
+	if c.finished && c.closing == 0 {
+		defer c.mu.Unlock()
+
+		return c.err
+	}
+
+	if c.done == nil {
+		c.done = make(chan struct{})
+	}
+
+	done := c.done
+
+	c.mu.Unlock()
+
+	select {
+	case <-done:
+		return c.Err()
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", op, ctx.Err())
+	}
 }
 
 // CloseOne closes one function and updates the index for the next operation.
+// The functions are closed one by one in the registration order.
 func (c *Closer) CloseOne(ctx context.Context) error {
 	op := "closer.CloseOne"
 
+	if c.reentrant(ctx) {
+		return fmt.Errorf("%s: %v", op, ErrReentrantClose)
+	}
+
 	c.mu.Lock()
 
-	// Save the current index for calling the function
-	prev := c.i
+	var skipped []Result // Results of the skipped functions
 
-	err := func() error {
-		defer c.mu.Unlock()
+	for i := c.i; i < len(c.funcs); i++ {
+		e := &c.funcs[i]
 
-		// Check if all functions have already been closed
-		if c.i >= c.size {
-			return fmt.Errorf("%s: %v", op, ErrAllServicesClosed)
+		if e.taken {
+			continue
 		}
 
-		// Increment the index for the next function
-		c.i++
+		// The disabled functions are skipped
+		if e.disabled {
+			c.take(e, new(outcome))
+			skipped = append(skipped, e.skip(ReasonDisabled))
+
+			continue
+		}
+
+		return c.closeEntry(ctx, e, skipped)
+	}
+
+	c.mu.Unlock()
+
+	c.emitSkipped(skipped)
+
+	// All functions have already been closed
+	return fmt.Errorf("%s: %v", op, ErrAllServicesClosed)
+}
+
+// CloseNamed closes the first not yet closed function with the given name
+// and marks it as closed, so Close skips it, e.g. for operational tooling
+// tearing down one subsystem on demand. A disabled function is skipped.
+func (c *Closer) CloseNamed(ctx context.Context, name string) error {
+	op := "closer.CloseNamed"
+
+	if c.reentrant(ctx) {
+		return fmt.Errorf("%s: %v", op, ErrReentrantClose)
+	}
+
+	c.mu.Lock()
+
+	for i := c.i; i < len(c.funcs); i++ {
+		if e := &c.funcs[i]; !e.taken && e.name == name {
+			return c.closeEntry(ctx, e, nil)
+		}
+	}
+
+	c.mu.Unlock()
+
+	return fmt.Errorf("%s: %v %q", op, ErrUnknownName, name)
+}
+
+// closeEntry takes the entry and closes it like CloseOne, a disabled entry
+// is skipped. The results of the functions skipped before are emitted
+// first. It must be called under the mutex, which it unlocks.
+func (c *Closer) closeEntry(ctx context.Context, e *entry, skipped []Result) error {
+	c.take(e, new(outcome))
+
+	if e.disabled {
+		skipped = append(skipped, e.skip(ReasonDisabled))
+		c.mu.Unlock()
+
+		c.emitSkipped(skipped)
 
 		return nil
-	}()
+	}
 
-	if err != nil {
-		return err
+	// Copied, as the list may grow while the function runs
+	taken := *e
+	t := &task{}
+	c.prepare(t, &taken)
+
+	weight, left := c.pendingWeights(*e) // Weight of the functions left including this one
+
+	c.single++
+
+	c.mu.Unlock()
+
+	defer c.singleReturned()
+
+	c.emitSkipped(skipped)
+
+	ctx, cancelFallback := c.fallbackContext(c.markClosing(ctx))
+	defer cancelFallback()
+
+	ctx, cancel := c.funcContext(ctx, weight, left)
+	defer cancel()
+
+	defer c.flushDurations()
+
+	return t.run(ctx)
+}
+
+// singleReturned accounts for the return of a function run by closeEntry.
+func (c *Closer) singleReturned() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.single--
+
+	if c.single == 0 && c.singleDone != nil {
+		close(c.singleDone)
+		c.singleDone = nil
+	}
+}
+
+// waitSingle waits for the functions run by closeEntry to return or ctx
+// to be done, whichever comes first.
+func (c *Closer) waitSingle(ctx context.Context) {
+	c.mu.Lock()
+
+	if c.single == 0 {
+		c.mu.Unlock()
+		return
+	}
+
+	if c.singleDone == nil {
+		c.singleDone = make(chan struct{})
+	}
+
+	done := c.singleDone
+
+	c.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// take marks the entry as taken for closing, which is the only way to
+// claim it, so it runs at most once. Its result goes to out: a function
+// taken again, see ReloadByTag, gets a fresh one, so the run that set the
+// previous one never races with the new one. It must be called under
+// the mutex.
+func (c *Closer) take(e *entry, out *outcome) {
+	e.taken = true
+	e.out = out
+	c.taken.Add(1)
+
+	// Move the index past the taken functions
+	for c.i < len(c.funcs) && c.funcs[c.i].taken {
+		c.i++
+	}
+}
+
+// Plan returns the functions that the next Close would run, in the order
+// it would run them, without executing anything. Stages follow each other
+// in ascending order, the functions of a stage keep the registration order
+// although they are closed concurrently. Every function is identified by
+// its name (see WithName) or its registration index, e.g. "#3".
+func (c *Closer) Plan() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var pending []entry
+
+	for _, e := range c.funcs[c.i:] {
+		if !e.taken && !e.disabled {
+			pending = append(pending, e)
+		}
+	}
+
+	if c.lifo {
+		slices.Reverse(pending)
+	}
+
+	plan := make([]string, 0, len(pending))
+
+	for _, st := range splitStages(pending) {
+		for _, e := range st.entries {
+			plan = append(plan, e.label())
+		}
+	}
+
+	return plan
+}
+
+// Disable disables the functions with the given name: they stay registered
+// but are skipped when closing and reported as skipped in Results.
+// It reports whether a not yet closed function with the name was found.
+func (c *Closer) Disable(name string) bool {
+	return c.setDisabled(name, true)
+}
+
+// Enable enables the functions with the given name disabled by Disable.
+// It reports whether a not yet closed function with the name was found.
+func (c *Closer) Enable(name string) bool {
+	return c.setDisabled(name, false)
+}
+
+func (c *Closer) setDisabled(name string, disabled bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found := false
+
+	for i := c.i; i < len(c.funcs); i++ {
+		if !c.funcs[i].taken && c.funcs[i].name == name {
+			c.funcs[i].disabled = disabled
+			found = true
+		}
 	}
 
-	return c.funcs[prev](ctx)
+	return found
+}
+
+// prepare prepares the task to run the function of the entry: with its
+// settings and the chaos applied, reporting its result when it returns.
+// It must be called under the mutex.
+func (c *Closer) prepare(t *task, e *entry) {
+	t.c = c
+	t.e = e
+	t.clock = c.getClock()
+	t.meta.e = e
+
+	if c.chaos != nil {
+		t.f = c.chaos.wrap(t.call, t.clock)
+	}
 }
 
 // Size returns the number of added functions to close.
+// It never blocks, so it is safe to poll while Close is running.
 func (c *Closer) Size() int {
-	return c.size
+	return int(c.size.Load())
 }
 
-// execF runs a function in a goroutine and returns a channel to receive any error.
-func execF(ctx context.Context, f Func, wg *sync.WaitGroup, errCh chan<- error) {
-	defer wg.Done()
+// Remaining returns the number of added functions that are not taken
+// for closing yet. It never blocks, so it is safe to poll while Close
+// is running, e.g. from a health endpoint.
+func (c *Closer) Remaining() int {
+	// The taken number is loaded first: both only grow and it never exceeds the size
+	taken := c.taken.Load()
 
-	// Execute the function and send any error to the channel
-	err := f(ctx)
+	return int(c.size.Load() - taken)
+}
 
-	if err != nil {
-		errCh <- err
+// ShuttingDown reports whether a Close (CloseExcept, CloseUntil) has
+// begun, including its grace period (see WithShutdownDelay), e.g. to fail
+// the readiness probe. A reload doesn't count.
+func (c *Closer) ShuttingDown() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.shutdown
+}
+
+// Finished reports whether a Close has finished and no other one is in
+// progress, that is whether WaitClosed would return at once.
+func (c *Closer) Finished() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.finished && c.closing == 0
+}
+
+// closeStage closes the functions of one stage concurrently
+// and returns the errors that occurred.
+// In the synchronous mode the functions are closed one by one
+// in the registration order instead.
+func (c *Closer) closeStage(ctx context.Context, run *closeRun, funcs []runner, weights []int) []error {
+	// A single function doesn't need a goroutine to run concurrently,
+	// unless it may be abandoned
+	if c.sync || len(funcs) == 1 && !c.abandon {
+		return c.execSequential(ctx, run, funcs, weights)
+	}
+
+	exec := c.getExecutor()
+
+	var (
+		slots   = make([]error, len(funcs))   // Errors of the functions, by position
+		results = make(chan slot, len(funcs)) // Buffered, so the workers never block
+		next    atomic.Int64                  // Index of the next function to run
+	)
+
+	// Run the functions in a pool of workers, each function reports its
+	// error with its position: no error is lost or misattributed, and the
+	// errors keep the order of the functions whatever the timing
+	for range c.workers(len(funcs)) {
+		exec.Go(func() {
+			execWorker(ctx, funcs, results, &next)
+		})
+	}
+
+	var abandon <-chan struct{} // Done channel once the functions are abandoned
+
+	if c.abandon {
+		abandon = ctx.Done()
+	}
+
+	for left := len(funcs); left > 0; left-- {
+		select {
+		case s := <-results:
+			slots[s.index] = c.keepError(run, s.err)
+		case <-abandon:
+			left -= c.drain(run, results, slots)
+
+			if left == 0 {
+				return compact(slots)
+			}
+
+			go c.collectLate(run, results, left)
+
+			return append(compact(slots), fmt.Errorf("%d functions abandoned: %w", left, ctx.Err()))
+		}
+	}
+
+	return compact(slots)
+}
+
+// slot is the error of the function at the index of its stage.
+type slot struct {
+	index int
+	err   error
+}
+
+// drain stores the results available at once into the slots and
+// returns their number.
+func (c *Closer) drain(run *closeRun, results <-chan slot, slots []error) int {
+	for n := 0; ; n++ {
+		select {
+		case s := <-results:
+			slots[s.index] = c.keepError(run, s.err)
+		default:
+			return n
+		}
+	}
+}
+
+// compact returns the errors that are not nil.
+func compact(errs []error) []error {
+	var fErrors []error
+
+	for _, err := range errs {
+		if err != nil {
+			fErrors = append(fErrors, err)
+		}
+	}
+
+	return fErrors
+}
+
+// workers returns the number of workers to close n functions.
+func (c *Closer) workers(n int) int {
+	switch {
+	case c.maxConcurrency < 0:
+		return n
+	case c.maxConcurrency == 0:
+		return min(n, DefaultMaxConcurrency)
+	default:
+		return min(n, c.maxConcurrency)
+	}
+}
+
+// execSequential runs the functions one by one on the calling goroutine
+// and returns the errors that occurred. The weights of the functions
+// divide the function budget, nil means they all have weight 1.
+func (c *Closer) execSequential(ctx context.Context, run *closeRun, funcs []runner, weights []int) []error {
+	var fErrors []error
+
+	left := 0 // Weight of the functions left
+
+	for i := range funcs {
+		left += weightAt(weights, i)
+	}
+
+	for i, f := range funcs {
+		fCtx, cancel := c.funcContext(ctx, weightAt(weights, i), left)
+		left -= weightAt(weights, i)
+
+		if err := c.keepError(run, f.run(fCtx)); err != nil {
+			fErrors = append(fErrors, err)
+		}
+
+		cancel()
+	}
+
+	return fErrors
+}
+
+// execWorker runs the functions until none is left, reporting the error
+// of every function with its index.
+func execWorker(ctx context.Context, funcs []runner, results chan<- slot, next *atomic.Int64) {
+	for {
+		i := int(next.Add(1)) - 1
+
+		if i >= len(funcs) {
+			return
+		}
+
+		results <- slot{index: i, err: funcs[i].run(ctx)}
 	}
 }
 
+// callSite returns the file and line of the caller skip frames above
+// the function calling callSite.
+func callSite(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+
+	if !ok {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
 func (c *Closer) reset() {
 	c.mu.Lock()
+
+	for i := range c.funcs {
+		c.funcs[i].taken = false
+	}
+
 	c.i = 0
+	c.taken.Store(0)
+
 	c.mu.Unlock()
 }
 
 type Func func(ctx context.Context) error
+
+// runner is a function run by Close: a task or a bare Func.
+type runner interface {
+	run(ctx context.Context) error
+}
+
+// run calls f, so a Func is a runner.
+func (f Func) run(ctx context.Context) error {
+	return f(ctx)
+}
+
+// runners returns the funcs as runners.
+func runners(funcs []Func) []runner {
+	rs := make([]runner, len(funcs))
+
+	for i, f := range funcs {
+		rs[i] = f
+	}
+
+	return rs
+}
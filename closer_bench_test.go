@@ -50,3 +50,125 @@ func BenchmarkCloser_CloseOne(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkCloser_Close_MaxConcurrency(b *testing.B) {
+	cl := New(WithMaxConcurrency(4))
+
+	for j := 0; j < 100; j++ {
+		cl.Add(func(ctx context.Context) error {
+			return nil
+		})
+	}
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := cl.Close(ctx)
+		cl.reset()
+
+		if err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkCloser_Add(b *testing.B) {
+	f := func(ctx context.Context) error {
+		return nil
+	}
+
+	for i := 0; i < b.N; i++ {
+		var cl Closer
+
+		for j := 0; j < 1000; j++ {
+			cl.Add(f)
+		}
+	}
+}
+
+func BenchmarkCloser_Add_NewWithCapacity(b *testing.B) {
+	f := func(ctx context.Context) error {
+		return nil
+	}
+
+	for i := 0; i < b.N; i++ {
+		cl := NewWithCapacity(1000)
+
+		for j := 0; j < 1000; j++ {
+			cl.Add(f)
+		}
+	}
+}
+
+func benchmarkCloseMany(b *testing.B, opts ...Option) {
+	f := func(ctx context.Context) error {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+
+		cl := NewWithCapacity(50_000, opts...)
+
+		for j := 0; j < 50_000; j++ {
+			cl.Add(f)
+		}
+
+		b.StartTimer()
+
+		if err := cl.Close(ctx); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkCloser_Close_50k(b *testing.B) {
+	benchmarkCloseMany(b)
+}
+
+func BenchmarkCloser_Close_50k_Unlimited(b *testing.B) {
+	benchmarkCloseMany(b, WithMaxConcurrency(0))
+}
+
+func Test_Close_AllocsPath(t *testing.T) {
+	const (
+		runs  = 10
+		funcs = 1000
+	)
+
+	f := func(ctx context.Context) error {
+		return nil
+	}
+
+	closers := make([]*Closer, runs+1) // AllocsPerRun runs once more to warm up
+
+	for i := range closers {
+		closers[i] = NewWithCapacity(funcs, WithMaxConcurrency(8))
+
+		for range funcs {
+			closers[i].Add(f)
+		}
+	}
+
+	ctx := context.Background()
+	next := 0
+
+	allocs := testing.AllocsPerRun(runs, func() {
+		if err := closers[next].Close(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		next++
+	})
+
+	// The allocations of Close don't grow with the number of functions
+	if allocs > funcs/10 {
+		t.Fatalf("Close of %d functions allocates %v times", funcs, allocs)
+	}
+}
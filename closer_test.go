@@ -2,9 +2,13 @@ package closer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -269,3 +273,525 @@ func Test_CloseOne_MultiThreaded_CancelWithCtxPath(t *testing.T) {
 
 	wg.Wait()
 }
+
+func Test_Plan_HappyPath(t *testing.T) {
+	var cl Closer
+	mcf := &mockCloseFunc{}
+
+	require.Empty(t, cl.Plan())
+
+	cl.AddStage(1, mcf.close)
+	cl.Add(mcf.close)
+	cl.AddStage(-1, mcf.close)
+	cl.Add(mcf.close)
+
+	require.Equal(t, []string{"#2", "#1", "#3", "#0"}, cl.Plan())
+	require.Equal(t, 0, mcf.calledCount)
+
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.Equal(t, []string{"#2", "#1", "#3"}, cl.Plan())
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Empty(t, cl.Plan())
+}
+
+func Test_Close_NotLockedDuringTeardownPath(t *testing.T) {
+	var cl Closer
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	cl.Add(func(ctx context.Context) error {
+		close(started)
+		<-release
+
+		return nil
+	})
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- cl.Close(context.Background())
+	}()
+
+	<-started
+
+	// The closer is usable while the teardown is in progress
+	mcf := &mockCloseFunc{}
+	cl.Add(mcf.close)
+
+	require.Equal(t, 2, cl.Size())
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.Equal(t, 1, mcf.calledCount)
+
+	close(release)
+
+	require.NoError(t, <-errCh)
+}
+
+func Test_Remaining_HappyPath(t *testing.T) {
+	var cl Closer
+	mocks := []*mockCloseFunc{{}, {}, {}}
+
+	require.Equal(t, 0, cl.Remaining())
+
+	for _, mcf := range mocks {
+		cl.Add(mcf.close)
+	}
+
+	require.Equal(t, 3, cl.Remaining())
+
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.Equal(t, 2, cl.Remaining())
+	require.Equal(t, 3, cl.Size())
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 0, cl.Remaining())
+	require.Equal(t, 3, cl.Size())
+}
+
+func Test_ShuttingDown_HappyPath(t *testing.T) {
+	var cl Closer
+
+	var during [2]bool
+
+	cl.Add(func(ctx context.Context) error {
+		during = [2]bool{cl.ShuttingDown(), cl.Finished()}
+		return nil
+	})
+
+	require.False(t, cl.ShuttingDown())
+	require.False(t, cl.Finished())
+
+	// CloseOne is not a shutdown
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.False(t, cl.ShuttingDown())
+
+	cl.Add(func(ctx context.Context) error {
+		during = [2]bool{cl.ShuttingDown(), cl.Finished()}
+		return nil
+	})
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, [2]bool{true, false}, during)
+	require.True(t, cl.ShuttingDown())
+	require.True(t, cl.Finished())
+}
+
+func Test_Remaining_MultiThreadedPath(t *testing.T) {
+	var cl Closer
+
+	release := make(chan struct{})
+
+	cl.Add(func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- cl.Close(context.Background())
+	}()
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			cl.Add(func(ctx context.Context) error { return nil })
+			require.GreaterOrEqual(t, cl.Remaining(), 0)
+			require.GreaterOrEqual(t, cl.Size(), 1)
+		}()
+	}
+
+	wg.Wait()
+	close(release)
+
+	require.NoError(t, <-errCh)
+	require.Equal(t, 11, cl.Size())
+}
+
+func Test_NewWithCapacity_HappyPath(t *testing.T) {
+	cl := NewWithCapacity(100, WithSynchronousExecution())
+
+	require.Equal(t, 100, cap(cl.funcs))
+	require.Equal(t, 0, cl.Size())
+
+	mcf := &mockCloseFunc{}
+
+	for range 100 {
+		cl.Add(mcf.close)
+	}
+
+	require.Equal(t, 100, cap(cl.funcs))
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 100, mcf.calledCount)
+}
+
+func Test_Add_NilFuncPath(t *testing.T) {
+	var cl Closer
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.Add: %v registered at %s", ErrNilFunc, lineAfter(t, 1)), func() {
+		cl.Add(nil)
+	})
+
+	require.Panics(t, func() {
+		cl.AddStage(1, nil)
+	})
+
+	require.Equal(t, 0, cl.Size())
+}
+
+func Test_MustAdd_HappyPath(t *testing.T) {
+	var cl Closer
+
+	mocks := []*mockCloseFunc{{}, {}}
+
+	require.NotNil(t, cl.MustAdd(mocks[0].close))
+	require.NotNil(t, cl.MustAddStage(1, mocks[1].close))
+	require.NoError(t, cl.Close(context.Background()))
+
+	for _, mcf := range mocks {
+		require.Equal(t, 1, mcf.calledCount)
+	}
+}
+
+func Test_MustAdd_ErrorPath(t *testing.T) {
+	var cl Closer
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.MustAdd: %v registered at %s", ErrNilFunc, lineAfter(t, 1)), func() {
+		cl.MustAdd(nil)
+	})
+
+	cl.Add(func(ctx context.Context) error { return nil })
+	require.NoError(t, cl.Close(context.Background()))
+
+	// The shutdown has begun, even without the strict mode
+	require.PanicsWithValue(t, fmt.Sprintf("closer.MustAdd: %v, registered at %s", ErrLateAdd, lineAfter(t, 1)), func() {
+		cl.MustAdd(func(ctx context.Context) error { return nil })
+	})
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.MustAddStage: %v, registered at %s", ErrLateAdd, lineAfter(t, 1)), func() {
+		cl.MustAddStage(1, func(ctx context.Context) error { return nil })
+	})
+
+	require.Equal(t, 1, cl.Size())
+}
+
+// lineAfter returns the call site n lines below the line calling it.
+func lineAfter(t *testing.T, n int) string {
+	t.Helper()
+
+	_, file, line, _ := runtime.Caller(1)
+
+	return fmt.Sprintf("%s:%d", file, line+n)
+}
+
+func Test_CloseExcept_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	mocks := []*mockCloseFunc{{}, {}, {}, {}}
+
+	cl.Add(mocks[0].close, WithName("logger"))
+	cl.Add(mocks[1].close, WithName("db"))
+	cl.Add(mocks[2].close, WithName("metrics"))
+	cl.Add(mocks[3].close)
+
+	require.NoError(t, cl.CloseExcept(context.Background(), "logger", "metrics"))
+
+	require.Equal(t, 0, mocks[0].calledCount)
+	require.Equal(t, 1, mocks[1].calledCount)
+	require.Equal(t, 0, mocks[2].calledCount)
+	require.Equal(t, 1, mocks[3].calledCount)
+	require.Equal(t, 2, cl.Remaining())
+	require.Equal(t, []string{"logger", "metrics"}, cl.Plan())
+
+	require.ErrorContains(t, cl.CloseExcept(context.Background(), "logger", "metrics"), ErrAllServicesClosed)
+
+	// The protected functions are closed manually at the very end
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.Equal(t, 1, mocks[0].calledCount)
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 1, mocks[2].calledCount)
+	require.Equal(t, 0, cl.Remaining())
+}
+
+func Test_CloseNamed_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	mocks := []*mockCloseFunc{{}, {}, {}}
+
+	cl.Add(mocks[0].close, WithName("db"))
+	cl.Add(mocks[1].close, WithName("cache"))
+	cl.Add(mocks[2].close, WithName("cache"))
+
+	require.NoError(t, cl.CloseNamed(context.Background(), "cache"))
+	require.Equal(t, []int{0, 1, 0}, []int{mocks[0].calledCount, mocks[1].calledCount, mocks[2].calledCount})
+	require.Equal(t, []string{"db", "cache"}, cl.Plan())
+
+	// The closed function is skipped by Close
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []int{1, 1, 1}, []int{mocks[0].calledCount, mocks[1].calledCount, mocks[2].calledCount})
+
+	err := cl.CloseNamed(context.Background(), "cache")
+
+	require.EqualError(t, err, fmt.Sprintf("closer.CloseNamed: %v %q", ErrUnknownName, "cache"))
+}
+
+func Test_CloseNamed_DisabledPath(t *testing.T) {
+	cl := New()
+	mock := &mockCloseFunc{}
+
+	cl.Add(mock.close, WithName("db"))
+	cl.Disable("db")
+
+	require.NoError(t, cl.CloseNamed(context.Background(), "db"))
+	require.Equal(t, 0, mock.calledCount)
+	require.Equal(t, StatusSkipped, cl.Results()[0].Status)
+	require.Equal(t, 0, cl.Remaining())
+}
+
+func Test_CloseUntil_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	var order []string
+
+	add := func(name string, opts ...FuncOption) {
+		cl.Add(func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}, append([]FuncOption{WithName(name)}, opts...)...)
+	}
+
+	add("db")
+	add("subsystem")
+	add("cache")
+	add("queue", WithPriority(1))
+
+	require.NoError(t, cl.CloseUntil(context.Background(), "subsystem"))
+	require.Equal(t, []string{"queue", "cache"}, order)
+	require.Equal(t, []string{"db", "subsystem"}, cl.Plan())
+
+	require.ErrorContains(t, cl.CloseUntil(context.Background(), "subsystem"), ErrAllServicesClosed)
+	require.ErrorContains(t, cl.CloseUntil(context.Background(), "unknown"), ErrUnknownName)
+}
+
+func Test_Err_HappyPath(t *testing.T) {
+	var cl Closer
+
+	require.NoError(t, cl.Err())
+
+	cl.Add(func(ctx context.Context) error { return fmt.Errorf("failed") })
+
+	err := cl.Close(context.Background())
+
+	require.Error(t, err)
+	require.Equal(t, err, cl.Err())
+
+	// A call finding nothing to close doesn't overwrite the result
+	require.ErrorContains(t, cl.Close(context.Background()), ErrAllServicesClosed)
+	require.Equal(t, err, cl.Err())
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.NoError(t, cl.Err())
+}
+
+func Test_Close_ExactlyOncePath(t *testing.T) {
+	const n = 200
+
+	cl := New(WithMaxConcurrency(4))
+	counts := make([]atomic.Int32, n)
+	handles := make([]Handle, n)
+
+	for i := range n {
+		handles[i] = cl.AddStage(i%3, func(ctx context.Context) error {
+			counts[i].Add(1)
+			return nil
+		}, WithName(fmt.Sprintf("f%d", i%10)))
+	}
+
+	var wg sync.WaitGroup
+
+	run := func(f func()) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+
+	for range 4 {
+		run(func() { _ = cl.Close(context.Background()) })
+		run(func() {
+			for cl.CloseOne(context.Background()) == nil {
+			}
+		})
+		run(func() {
+			for i := range 10 {
+				_ = cl.CloseNamed(context.Background(), fmt.Sprintf("f%d", i))
+			}
+		})
+		run(func() {
+			for _, h := range handles {
+				_ = h.Close(context.Background())
+			}
+		})
+	}
+
+	wg.Wait()
+
+	for i := range counts {
+		require.Equal(t, int32(1), counts[i].Load(), "function %d", i)
+	}
+}
+
+func Test_Close_WaitsForCloseOnePath(t *testing.T) {
+	var (
+		cl       Closer
+		started  = make(chan struct{})
+		release  = make(chan struct{})
+		returned atomic.Bool
+	)
+
+	cl.Add(func(ctx context.Context) error {
+		close(started)
+		<-release
+		returned.Store(true)
+
+		return errors.New("slow")
+	})
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	errOne := make(chan error, 1)
+
+	go func() { errOne <- cl.CloseOne(context.Background()) }()
+
+	<-started
+
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+
+	// The function claimed by CloseOne is skipped but waited for,
+	// its error goes to CloseOne only
+	require.NoError(t, cl.Close(context.Background()))
+	require.True(t, returned.Load())
+	require.EqualError(t, <-errOne, "slow")
+}
+
+func Test_Close_WaitsForCloseOne_CancelWithCtxPath(t *testing.T) {
+	var cl Closer
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	cl.Add(func(ctx context.Context) error {
+		close(started)
+		<-release
+
+		return nil
+	})
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	go func() { _ = cl.CloseOne(context.Background()) }()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The function claimed by CloseOne is waited for as long as ctx allows
+	require.NoError(t, cl.Close(ctx))
+	require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func Test_Close_NothingLeft_CalledFromCloseOnePath(t *testing.T) {
+	closeOne := map[string]func(cl *Closer, h Handle) error{
+		"Handle.Close": func(cl *Closer, h Handle) error { return h.Close(context.Background()) },
+		"CloseOne":     func(cl *Closer, h Handle) error { return cl.CloseOne(context.Background()) },
+		"CloseNamed":   func(cl *Closer, h Handle) error { return cl.CloseNamed(context.Background(), "db") },
+	}
+
+	for name, call := range closeOne {
+		t.Run(name, func(t *testing.T) {
+			var cl Closer
+
+			// The only function closes the closer, which is left with nothing
+			// to close and must not wait for the function calling it
+			h := cl.Add(func(ctx context.Context) error {
+				return cl.Close(context.Background())
+			}, WithName("db"))
+
+			done := make(chan error, 1)
+
+			go func() { done <- call(&cl, h) }()
+
+			select {
+			case err := <-done:
+				require.ErrorContains(t, err, ErrAllServicesClosed)
+			case <-time.After(time.Second):
+				t.Fatal("Close called from a function run by " + name + " deadlocked")
+			}
+		})
+	}
+}
+
+func Test_Close_ErrorsInOrderPath(t *testing.T) {
+	const n = 100
+
+	cl := New(WithMaxConcurrency(8))
+
+	for i := range n {
+		cl.Add(func(ctx context.Context) error {
+			// The later functions fail first
+			time.Sleep(time.Duration(n-i) * 50 * time.Microsecond)
+			return fmt.Errorf("error %d", i)
+		}, WithName(fmt.Sprintf("f%d", i)))
+	}
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, cl.Close(context.Background()), &agg)
+
+	failed := agg.Failed()
+	require.Len(t, failed, n)
+
+	for i, fErr := range failed {
+		require.Equal(t, i, fErr.Index)
+		require.EqualError(t, fErr, fmt.Sprintf("f%d: error %d", i, i))
+	}
+}
+
+func Test_CloseFunc_HappyPath(t *testing.T) {
+	var (
+		app    Closer
+		module Closer
+		closed []string
+	)
+
+	module.Add(func(ctx context.Context) error {
+		closed = append(closed, "module db")
+		return errors.New("busy")
+	}, WithName("db"))
+
+	app.Add(module.CloseFunc(), WithName("module"), WithStage(0))
+	app.AddStage(1, func(ctx context.Context) error {
+		closed = append(closed, "app logger")
+		return nil
+	})
+
+	err := app.Close(context.Background())
+
+	require.EqualError(t, err, "closer.Close: module: closer.Close: db: busy")
+	require.Equal(t, []string{"module db", "app logger"}, closed)
+
+	// An empty module is not an error
+	var empty Closer
+
+	require.NoError(t, empty.CloseFunc()(context.Background()))
+}
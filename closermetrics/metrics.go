@@ -0,0 +1,121 @@
+// Package closermetrics records the shutdown SLIs of a closer.Closer:
+// the shutdown duration, the duration of every function and the failures.
+// The instruments are small interfaces, so any metrics backend plugs in
+// without the closer module depending on it; the OpenTelemetry binding
+// is the separate github.com/ilKhr/closer/closerotel module.
+package closermetrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ilKhr/closer"
+)
+
+// Attribute is a key-value pair describing a measurement,
+// e.g. an OpenTelemetry attribute.KeyValue.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Histogram records the distribution of values,
+// e.g. an OpenTelemetry metric.Float64Histogram.
+type Histogram interface {
+	Record(ctx context.Context, value float64, attrs ...Attribute)
+}
+
+// Counter counts occurrences, e.g. an OpenTelemetry metric.Int64Counter.
+type Counter interface {
+	Add(ctx context.Context, incr int64, attrs ...Attribute)
+}
+
+// Instruments are the instruments the metrics are recorded with.
+// A nil instrument is not recorded.
+type Instruments struct {
+	ShutdownDuration Histogram // Duration of Close in seconds, by "status"
+	FuncDuration     Histogram // Duration of a function in seconds, by "name" and "status"
+	FuncFailures     Counter   // Failed functions, by "name" and "category"
+}
+
+// Sink returns the closer.Sink recording the metrics of the events with
+// the instruments, see closer.WithSink.
+func Sink(in Instruments) closer.Sink {
+	r := &recorder{in: in}
+
+	return closer.SinkFunc(r.handle)
+}
+
+// recorder records the metrics of the events.
+type recorder struct {
+	in Instruments
+
+	mu     sync.Mutex
+	starts []closer.Event // Start events of the shutdowns in progress
+}
+
+func (r *recorder) handle(ev closer.Event) {
+	ctx := context.Background()
+
+	switch ev.Type {
+	case closer.EventShutdownStarted:
+		r.mu.Lock()
+		r.starts = append(r.starts, ev)
+		r.mu.Unlock()
+
+	case closer.EventShutdownFinished:
+		r.mu.Lock()
+
+		// Concurrent shutdowns are told apart by the order only
+		var start closer.Event
+
+		if len(r.starts) > 0 {
+			start, r.starts = r.starts[0], r.starts[1:]
+		}
+
+		r.mu.Unlock()
+
+		if r.in.ShutdownDuration != nil && !start.Time.IsZero() {
+			r.in.ShutdownDuration.Record(ctx, ev.Time.Sub(start.Time).Seconds(), Attribute{Key: "status", Value: status(ev.Err)})
+		}
+
+	case closer.EventFuncFinished:
+		res := ev.Result
+
+		// The skipped functions didn't run, yet they fail with WithSkipOnCancel
+		if r.in.FuncDuration != nil && res.Status != closer.StatusSkipped {
+			r.in.FuncDuration.Record(ctx, res.Duration.Seconds(),
+				Attribute{Key: "name", Value: res.Name},
+				Attribute{Key: "status", Value: res.Status.String()},
+			)
+		}
+
+		if r.in.FuncFailures != nil && res.Err != nil {
+			r.in.FuncFailures.Add(ctx, 1,
+				Attribute{Key: "name", Value: res.Name},
+				Attribute{Key: "category", Value: category(res.Err)},
+			)
+		}
+	}
+}
+
+// status returns the status attribute of a shutdown with the error.
+func status(err error) string {
+	if err != nil {
+		return "failed"
+	}
+
+	return "closed"
+}
+
+// category returns the category attribute of the error of a function.
+func category(err error) string {
+	var fErr closer.FuncError
+
+	if errors.As(err, &fErr) {
+		return fErr.Category.String()
+	}
+
+	return closer.CategoryFailed.String()
+}
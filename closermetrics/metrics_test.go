@@ -0,0 +1,83 @@
+package closermetrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ilKhr/closer"
+	"github.com/stretchr/testify/require"
+)
+
+type measurement struct {
+	value float64
+	attrs []Attribute
+}
+
+type fakeInstrument struct {
+	mu           sync.Mutex
+	measurements []measurement
+}
+
+func (f *fakeInstrument) Record(ctx context.Context, value float64, attrs ...Attribute) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.measurements = append(f.measurements, measurement{value: value, attrs: attrs})
+}
+
+func (f *fakeInstrument) Add(ctx context.Context, incr int64, attrs ...Attribute) {
+	f.Record(ctx, float64(incr), attrs...)
+}
+
+func Test_Sink_HappyPath(t *testing.T) {
+	var shutdown, funcs, failures fakeInstrument
+
+	cl := closer.New(closer.WithSink(Sink(Instruments{
+		ShutdownDuration: &shutdown,
+		FuncDuration:     &funcs,
+		FuncFailures:     &failures,
+	})))
+
+	cl.Add(func(ctx context.Context) error { return nil }, closer.WithName("db"))
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") }, closer.WithName("cache"))
+
+	require.Error(t, cl.Close(context.Background()))
+
+	require.Len(t, shutdown.measurements, 1)
+	require.Equal(t, []Attribute{{Key: "status", Value: "failed"}}, shutdown.measurements[0].attrs)
+
+	require.Len(t, funcs.measurements, 2)
+	require.ElementsMatch(t, []Attribute{
+		{Key: "name", Value: "db"}, {Key: "status", Value: "closed"},
+		{Key: "name", Value: "cache"}, {Key: "status", Value: "failed"},
+	}, append(funcs.measurements[0].attrs, funcs.measurements[1].attrs...))
+
+	require.Equal(t, []measurement{{value: 1, attrs: []Attribute{
+		{Key: "name", Value: "cache"},
+		{Key: "category", Value: "failed"},
+	}}}, failures.measurements)
+}
+
+func Test_Sink_SkippedPath(t *testing.T) {
+	var funcs, failures fakeInstrument
+
+	// The shutdown duration is not recorded without an instrument
+	cl := closer.New(closer.WithSkipOnCancel(), closer.WithSink(Sink(Instruments{
+		FuncDuration: &funcs,
+		FuncFailures: &failures,
+	})))
+
+	cl.Add(func(ctx context.Context) error { return nil }, closer.WithName("db"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Error(t, cl.Close(ctx))
+	require.Empty(t, funcs.measurements)
+	require.Equal(t, []Attribute{
+		{Key: "name", Value: "db"},
+		{Key: "category", Value: "skipped"},
+	}, failures.measurements[0].attrs)
+}
@@ -0,0 +1,98 @@
+// Package closerotel binds the closermetrics instruments to OpenTelemetry.
+// It is a separate module, so the closer module doesn't depend on
+// OpenTelemetry:
+//
+//	in, err := closerotel.Instruments(otel.Meter("app"))
+//	if err != nil {
+//		return err
+//	}
+//
+//	cl := closer.New(closer.WithSink(closermetrics.Sink(in)))
+package closerotel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ilKhr/closer/closermetrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Names of the instruments.
+const (
+	ShutdownDuration = "closer.shutdown.duration"
+	FuncDuration     = "closer.func.duration"
+	FuncFailures     = "closer.func.failures"
+)
+
+// Instruments creates the closermetrics instruments with the meter:
+// the histograms of the shutdown and function durations, in seconds,
+// and the counter of the failed functions.
+func Instruments(meter metric.Meter) (closermetrics.Instruments, error) {
+	op := "closerotel.Instruments"
+
+	shutdown, err := meter.Float64Histogram(ShutdownDuration,
+		metric.WithDescription("Duration of the shutdown"), metric.WithUnit("s"))
+
+	if err != nil {
+		return closermetrics.Instruments{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	funcs, err := meter.Float64Histogram(FuncDuration,
+		metric.WithDescription("Duration of a close function"), metric.WithUnit("s"))
+
+	if err != nil {
+		return closermetrics.Instruments{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	failures, err := meter.Int64Counter(FuncFailures,
+		metric.WithDescription("Failed close functions"))
+
+	if err != nil {
+		return closermetrics.Instruments{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return closermetrics.Instruments{
+		ShutdownDuration: Histogram(shutdown),
+		FuncDuration:     Histogram(funcs),
+		FuncFailures:     Counter(failures),
+	}, nil
+}
+
+// Histogram adapts an OpenTelemetry histogram to closermetrics.Histogram.
+func Histogram(h metric.Float64Histogram) closermetrics.Histogram {
+	return histogram{h: h}
+}
+
+// Counter adapts an OpenTelemetry counter to closermetrics.Counter.
+func Counter(c metric.Int64Counter) closermetrics.Counter {
+	return counter{c: c}
+}
+
+type histogram struct {
+	h metric.Float64Histogram
+}
+
+func (h histogram) Record(ctx context.Context, value float64, attrs ...closermetrics.Attribute) {
+	h.h.Record(ctx, value, metric.WithAttributes(keyValues(attrs)...))
+}
+
+type counter struct {
+	c metric.Int64Counter
+}
+
+func (c counter) Add(ctx context.Context, incr int64, attrs ...closermetrics.Attribute) {
+	c.c.Add(ctx, incr, metric.WithAttributes(keyValues(attrs)...))
+}
+
+// keyValues converts the attributes to OpenTelemetry ones.
+func keyValues(attrs []closermetrics.Attribute) []attribute.KeyValue {
+	kv := make([]attribute.KeyValue, len(attrs))
+
+	for i, a := range attrs {
+		kv[i] = attribute.String(a.Key, a.Value)
+	}
+
+	return kv
+}
@@ -0,0 +1,55 @@
+package closerotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ilKhr/closer"
+	"github.com/ilKhr/closer/closermetrics"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func Test_Instruments_HappyPath(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	in, err := Instruments(meter)
+	require.NoError(t, err)
+
+	cl := closer.New(closer.WithSink(closermetrics.Sink(in)))
+
+	cl.Add(func(ctx context.Context) error { return nil }, closer.WithName("db"))
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") }, closer.WithName("cache"))
+
+	require.Error(t, cl.Close(context.Background()))
+
+	var rm metricdata.ResourceMetrics
+
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	metrics := make(map[string]metricdata.Aggregation)
+
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	shutdown := metrics[ShutdownDuration].(metricdata.Histogram[float64])
+	require.Len(t, shutdown.DataPoints, 1)
+	require.Equal(t, attribute.NewSet(attribute.String("status", "failed")), shutdown.DataPoints[0].Attributes)
+
+	funcs := metrics[FuncDuration].(metricdata.Histogram[float64])
+	require.Len(t, funcs.DataPoints, 2)
+
+	failures := metrics[FuncFailures].(metricdata.Sum[int64])
+	require.Len(t, failures.DataPoints, 1)
+	require.Equal(t, int64(1), failures.DataPoints[0].Value)
+	require.Equal(t, attribute.NewSet(
+		attribute.String("name", "cache"),
+		attribute.String("category", "failed"),
+	), failures.DataPoints[0].Attributes)
+}
@@ -0,0 +1,79 @@
+// Package closers provides closer.Func constructors for the resources
+// commonly released on shutdown, so they can be registered with
+// a closer.Closer next to each other.
+package closers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// retries is the number of times a failing removal is retried.
+const retries = 3
+
+// retryDelay is the delay between the attempts of a failing removal.
+const retryDelay = 100 * time.Millisecond
+
+// retry calls f until it succeeds, it was retried retries times or ctx is
+// done, waiting for retryDelay between the attempts.
+func retry(ctx context.Context, f func() error) error {
+	err := f()
+
+	for i := 0; err != nil && i < retries; i++ {
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retryDelay):
+		}
+
+		err = f()
+	}
+
+	return err
+}
+
+// drainInterval is how often the count of the busy items is polled.
+const drainInterval = 10 * time.Millisecond
+
+// drain waits for the count of the busy items to drop to zero as long
+// as ctx allows and returns the number of the ones left.
+func drain(ctx context.Context, count func() int) int {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for {
+		n := count()
+
+		if n == 0 {
+			return 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return n
+		case <-ticker.C:
+		}
+	}
+}
+
+// join combines the errors into one wrapping all of them,
+// nil if there are none.
+func join(op string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	verbs := make([]string, len(errs))
+	args := make([]any, 0, len(errs)+1)
+
+	args = append(args, op)
+
+	for i, err := range errs {
+		verbs[i] = "%w"
+		args = append(args, err)
+	}
+
+	return fmt.Errorf("%s: "+strings.Join(verbs, ";\x20"), args...)
+}
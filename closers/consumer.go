@@ -0,0 +1,54 @@
+package closers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ilKhr/closer"
+)
+
+// Consumer is a message consumer, e.g. a thin wrapper around a Kafka,
+// NATS or AMQP client.
+type Consumer interface {
+	Stop(ctx context.Context) error // Stops fetching new messages
+	InFlight() int                  // Number of messages being processed
+	Close() error                   // Closes the connection
+}
+
+// Committer is implemented by the consumers that commit their offsets
+// or acknowledgements explicitly.
+type Committer interface {
+	Commit(ctx context.Context) error
+}
+
+// StopConsumer returns a function stopping the consumer: it stops fetching
+// new messages, waits for the in-flight ones as long as ctx allows, commits
+// the offsets if the consumer is a Committer and only then closes the
+// connection, so the work already done is not redelivered. Every step runs
+// even if the previous one failed; the messages still in flight when ctx
+// is done are reported as abandoned.
+func StopConsumer(c Consumer) closer.Func {
+	return func(ctx context.Context) error {
+		var errs []error
+
+		if err := c.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop: %w", err))
+		}
+
+		if n := drain(ctx, c.InFlight); n > 0 {
+			errs = append(errs, fmt.Errorf("%d messages abandoned in flight: %w", n, ctx.Err()))
+		}
+
+		if committer, ok := c.(Committer); ok {
+			if err := committer.Commit(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("commit: %w", err))
+			}
+		}
+
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close: %w", err))
+		}
+
+		return join("closers.StopConsumer", errs)
+	}
+}
@@ -0,0 +1,75 @@
+package closers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type consumer struct {
+	mu       sync.Mutex
+	calls    []string
+	inFlight atomic.Int32
+	closeErr error
+}
+
+func (c *consumer) record(call string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, call)
+}
+
+func (c *consumer) Stop(ctx context.Context) error {
+	c.record("stop")
+	return nil
+}
+
+func (c *consumer) InFlight() int {
+	return int(c.inFlight.Load())
+}
+
+func (c *consumer) Commit(ctx context.Context) error {
+	c.record("commit")
+	return nil
+}
+
+func (c *consumer) Close() error {
+	c.record("close")
+	return c.closeErr
+}
+
+func Test_StopConsumer_HappyPath(t *testing.T) {
+	c := &consumer{}
+	c.inFlight.Store(2)
+
+	go func() {
+		for range 2 {
+			time.Sleep(5 * time.Millisecond)
+			c.record("ack")
+			c.inFlight.Add(-1)
+		}
+	}()
+
+	require.NoError(t, StopConsumer(c)(context.Background()))
+	require.Equal(t, []string{"stop", "ack", "ack", "commit", "close"}, c.calls)
+}
+
+func Test_StopConsumer_CancelWithCtxPath(t *testing.T) {
+	c := &consumer{closeErr: errors.New("broken pipe")}
+	c.inFlight.Store(3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := StopConsumer(c)(ctx)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, "closers.StopConsumer: 3 messages abandoned in flight: context deadline exceeded; close: broken pipe")
+	require.Equal(t, []string{"stop", "commit", "close"}, c.calls)
+}
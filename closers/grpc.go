@@ -0,0 +1,45 @@
+package closers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ilKhr/closer"
+)
+
+const (
+	ErrHardStop = "graceful stop timed out, stopped forcibly"
+)
+
+// GRPCStopper is a gRPC server, e.g. *grpc.Server.
+type GRPCStopper interface {
+	GracefulStop() // Stops accepting connections and waits for the pending RPCs
+	Stop()         // Closes the connections and cancels the pending RPCs
+}
+
+// GRPCServer returns a function stopping the gRPC server gracefully: it
+// waits for the pending RPCs as long as ctx allows, then falls back to
+// Stop and reports with ErrHardStop that the hard stop was needed.
+func GRPCServer(s GRPCStopper) closer.Func {
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+
+		go func() {
+			defer close(stopped)
+
+			s.GracefulStop()
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+		}
+
+		// Stop cancels the pending RPCs, so GracefulStop returns as well
+		s.Stop()
+		<-stopped
+
+		return fmt.Errorf("%s: %v: %w", "closers.GRPCServer", ErrHardStop, ctx.Err())
+	}
+}
@@ -0,0 +1,47 @@
+package closers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// grpcServer mimics the stop methods of *grpc.Server: GracefulStop waits
+// for the pending RPCs until Stop cancels them.
+type grpcServer struct {
+	pending chan struct{}
+	stopped bool
+}
+
+func (s *grpcServer) GracefulStop() {
+	<-s.pending
+}
+
+func (s *grpcServer) Stop() {
+	s.stopped = true
+	close(s.pending)
+}
+
+func Test_GRPCServer_HappyPath(t *testing.T) {
+	s := &grpcServer{pending: make(chan struct{})}
+
+	close(s.pending)
+
+	require.NoError(t, GRPCServer(s)(context.Background()))
+	require.False(t, s.stopped)
+}
+
+func Test_GRPCServer_CancelWithCtxPath(t *testing.T) {
+	s := &grpcServer{pending: make(chan struct{})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := GRPCServer(s)(ctx)
+
+	require.True(t, s.stopped)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, "closers.GRPCServer: graceful stop timed out, stopped forcibly: context deadline exceeded")
+}
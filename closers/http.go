@@ -0,0 +1,61 @@
+package closers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ilKhr/closer"
+)
+
+// HTTPServer starts serving srv on l in a goroutine and returns a function
+// shutting it down gracefully: it stops accepting connections and waits
+// for the requests in flight as long as ctx allows, reporting the ones
+// still in flight at the deadline. http.ErrServerClosed is not an error;
+// any other error of Serve is reported when the server is closed.
+// The handler of srv is wrapped to count the requests in flight, so it
+// must be set before the call.
+func HTTPServer(srv *http.Server, l net.Listener) closer.Func {
+	var inFlight atomic.Int64
+
+	handler := srv.Handler
+
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		handler.ServeHTTP(w, r)
+	})
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- srv.Serve(l)
+	}()
+
+	return func(ctx context.Context) error {
+		var errs []error
+
+		if err := srv.Shutdown(ctx); err != nil {
+			if n := inFlight.Load(); n > 0 {
+				err = fmt.Errorf("%d requests still in flight: %w", n, err)
+			}
+
+			errs = append(errs, fmt.Errorf("shutdown: %w", err))
+		}
+
+		// Serve returns as soon as Shutdown is called
+		if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, fmt.Errorf("serve: %w", err))
+		}
+
+		return join("closers.HTTPServer", errs)
+	}
+}
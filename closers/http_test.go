@@ -0,0 +1,84 @@
+package closers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listen returns a listener on a random local port.
+func listen(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	return l
+}
+
+func Test_HTTPServer_HappyPath(t *testing.T) {
+	l := listen(t)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})}
+
+	closeServer := HTTPServer(srv, l)
+
+	resp, err := http.Get("http://" + l.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.NoError(t, closeServer(context.Background()))
+
+	_, err = http.Get("http://" + l.Addr().String())
+	require.Error(t, err)
+}
+
+func Test_HTTPServer_CancelWithCtxPath(t *testing.T) {
+	l := listen(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})}
+
+	closeServer := HTTPServer(srv, l)
+
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String())
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := closeServer(ctx)
+
+	close(release)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, "closers.HTTPServer: shutdown: 1 requests still in flight: context deadline exceeded")
+}
+
+func Test_HTTPServer_ErrorPath(t *testing.T) {
+	l := listen(t)
+	require.NoError(t, l.Close())
+
+	closeServer := HTTPServer(&http.Server{}, l)
+
+	// Let Serve fail before the server is shut down
+	time.Sleep(20 * time.Millisecond)
+
+	require.ErrorContains(t, closeServer(context.Background()), "closers.HTTPServer: serve: ")
+}
@@ -0,0 +1,63 @@
+package closers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ilKhr/closer"
+)
+
+// Flusher returns a function flushing w and then closing c, e.g. a
+// bufio.Writer over a file or a metrics buffer over its connection,
+// so the buffered data is not lost. c is closed even if the flush fails,
+// the error tells which of them failed. A nil c is not closed.
+func Flusher(w interface{ Flush() error }, c io.Closer) closer.Func {
+	return func(ctx context.Context) error {
+		var errs []error
+
+		if err := w.Flush(); err != nil {
+			errs = append(errs, fmt.Errorf("flush: %w", err))
+		}
+
+		if c != nil {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close: %w", err))
+			}
+		}
+
+		return join("closers.Flusher", errs)
+	}
+}
+
+// File returns a function syncing f to the storage and then closing it.
+// The errors of the sync and the close are reported distinctly. If ctx is
+// done first, e.g. on a hung network filesystem, the sync is abandoned and
+// reported, and the file is closed anyway.
+func File(f *os.File) closer.Func {
+	return func(ctx context.Context) error {
+		var errs []error
+
+		synced := make(chan error, 1)
+
+		go func() {
+			synced <- f.Sync()
+		}()
+
+		select {
+		case err := <-synced:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sync: %w", err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("sync abandoned: %w", ctx.Err()))
+		}
+
+		if err := f.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close: %w", err))
+		}
+
+		return join(fmt.Sprintf("%s: %s", "closers.File", f.Name()), errs)
+	}
+}
@@ -0,0 +1,98 @@
+package closers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type buffer struct {
+	bytes.Buffer
+	writeErr error
+	closeErr error
+	closed   bool
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	if b.writeErr != nil {
+		return 0, b.writeErr
+	}
+
+	return b.Buffer.Write(p)
+}
+
+func (b *buffer) Close() error {
+	b.closed = true
+	return b.closeErr
+}
+
+func Test_Flusher_HappyPath(t *testing.T) {
+	buf := &buffer{}
+	w := bufio.NewWriter(buf)
+
+	_, err := w.WriteString("data")
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	require.NoError(t, Flusher(w, buf)(context.Background()))
+	require.Equal(t, "data", buf.String())
+	require.True(t, buf.closed)
+}
+
+func Test_Flusher_ErrorPath(t *testing.T) {
+	buf := &buffer{writeErr: errors.New("disk full"), closeErr: errors.New("bad descriptor")}
+	w := bufio.NewWriter(buf)
+
+	_, err := w.WriteString("data")
+	require.NoError(t, err)
+
+	err = Flusher(w, buf)(context.Background())
+
+	require.EqualError(t, err, "closers.Flusher: flush: disk full; close: bad descriptor")
+	require.ErrorIs(t, err, buf.writeErr)
+	require.ErrorIs(t, err, buf.closeErr)
+	require.True(t, buf.closed)
+}
+
+func Test_File_HappyPath(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "data"))
+	require.NoError(t, err)
+
+	_, err = f.WriteString("data")
+	require.NoError(t, err)
+
+	require.NoError(t, File(f)(context.Background()))
+
+	// The file is closed
+	_, err = f.WriteString("data")
+	require.ErrorIs(t, err, os.ErrClosed)
+
+	err = File(f)(context.Background())
+
+	require.ErrorIs(t, err, os.ErrClosed)
+	require.ErrorContains(t, err, "closers.File: "+f.Name()+": sync: ")
+}
+
+func Test_File_CancelWithCtxPath(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "data"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = File(f)(ctx)
+
+	// The sync may win the race with the done context
+	if err != nil {
+		require.ErrorIs(t, err, context.Canceled)
+	}
+
+	_, err = f.WriteString("data")
+	require.ErrorIs(t, err, os.ErrClosed)
+}
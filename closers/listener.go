@@ -0,0 +1,103 @@
+package closers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/ilKhr/closer"
+)
+
+// TrackListener wraps l to track the connections it accepts and returns
+// the wrapper, to serve on, with a function draining it: it stops
+// accepting connections, waits for the tracked ones to be closed as long
+// as ctx allows, then force-closes the stragglers and reports their count.
+func TrackListener(l net.Listener) (net.Listener, closer.Func) {
+	tl := &trackedListener{Listener: l, conns: make(map[*trackedConn]struct{})}
+
+	return tl, tl.drain
+}
+
+// trackedListener is a listener tracking the accepted connections.
+type trackedListener struct {
+	net.Listener
+
+	mu    sync.Mutex
+	conns map[*trackedConn]struct{} // Connections not closed yet
+}
+
+func (l *trackedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &trackedConn{Conn: conn, l: l}
+
+	l.mu.Lock()
+	l.conns[tc] = struct{}{}
+	l.mu.Unlock()
+
+	return tc, nil
+}
+
+// active returns the number of the connections not closed yet.
+func (l *trackedListener) active() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.conns)
+}
+
+// drain stops accepting, waits for the connections and force-closes
+// the ones left once ctx is done.
+func (l *trackedListener) drain(ctx context.Context) error {
+	op := "closers.TrackListener"
+
+	var errs []error
+
+	if err := l.Listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		errs = append(errs, fmt.Errorf("close: %w", err))
+	}
+
+	if n := drain(ctx, l.active); n > 0 {
+		l.mu.Lock()
+
+		stragglers := make([]*trackedConn, 0, len(l.conns))
+
+		for tc := range l.conns {
+			stragglers = append(stragglers, tc)
+		}
+
+		l.mu.Unlock()
+
+		for _, tc := range stragglers {
+			_ = tc.Close()
+		}
+
+		errs = append(errs, fmt.Errorf("%d connections force-closed: %w", len(stragglers), ctx.Err()))
+	}
+
+	return join(op, errs)
+}
+
+// trackedConn is a connection untracked once closed.
+type trackedConn struct {
+	net.Conn
+
+	l    *trackedListener
+	once sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.l.mu.Lock()
+		delete(c.l.conns, c)
+		c.l.mu.Unlock()
+	})
+
+	return c.Conn.Close()
+}
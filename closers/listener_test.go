@@ -0,0 +1,55 @@
+package closers
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TrackListener_HappyPath(t *testing.T) {
+	l, drainListener := TrackListener(listen(t))
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = conn.Close()
+	}()
+
+	require.NoError(t, drainListener(context.Background()))
+
+	// No connection is accepted any more
+	_, err = l.Accept()
+	require.Error(t, err)
+}
+
+func Test_TrackListener_CancelWithCtxPath(t *testing.T) {
+	l, drainListener := TrackListener(listen(t))
+
+	for range 2 {
+		client, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		_, err = l.Accept()
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := drainListener(ctx)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, "closers.TrackListener: 2 connections force-closed: context deadline exceeded")
+}
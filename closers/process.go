@@ -0,0 +1,63 @@
+package closers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/ilKhr/closer"
+)
+
+const (
+	ErrKilled = "killed after the grace period"
+)
+
+// Process returns a function terminating the started child process cmd:
+// it sends SIGTERM, waits for the process to exit for up to grace or until
+// ctx is done, then sends SIGKILL. Either way the process is reaped by
+// cmd.Wait, so nobody else may wait for it. A process that exits after
+// SIGTERM is closed, whatever its exit status; a killed one is reported
+// with ErrKilled. A process that was not started or has already been
+// waited for is left as is.
+func Process(cmd *exec.Cmd, grace time.Duration) closer.Func {
+	return func(ctx context.Context) error {
+		op := "closers.Process"
+
+		if cmd.Process == nil || cmd.ProcessState != nil {
+			return nil
+		}
+
+		exited := make(chan error, 1)
+
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		go func() {
+			exited <- cmd.Wait()
+		}()
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+
+		select {
+		case <-exited:
+			return nil
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+
+		if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Reap the process, it can't outlive SIGKILL
+		<-exited
+
+		return fmt.Errorf("%s: pid %d: %v", op, cmd.Process.Pid, ErrKilled)
+	}
+}
@@ -0,0 +1,63 @@
+package closers
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Process_HappyPath(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	require.NoError(t, cmd.Start())
+
+	require.NoError(t, Process(cmd, time.Second)(context.Background()))
+	require.NotNil(t, cmd.ProcessState)
+
+	// The process has already been reaped
+	require.NoError(t, Process(cmd, time.Second)(context.Background()))
+
+	// The process was not started
+	require.NoError(t, Process(exec.Command("sleep", "10"), time.Second)(context.Background()))
+}
+
+func Test_Process_KillPath(t *testing.T) {
+	cmd := exec.Command("sh", "-c", `trap "" TERM; echo ready; exec sleep 10`)
+
+	out, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	// Wait for the trap to be installed
+	_, err = out.Read(make([]byte, 5))
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = Process(cmd, 50*time.Millisecond)(context.Background())
+
+	require.EqualError(t, err, fmt.Sprintf("closers.Process: pid %d: %v", cmd.Process.Pid, ErrKilled))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.NotNil(t, cmd.ProcessState)
+}
+
+func Test_Process_CancelWithCtxPath(t *testing.T) {
+	cmd := exec.Command("sh", "-c", `trap "" TERM; echo ready; exec sleep 10`)
+
+	out, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	_, err = out.Read(make([]byte, 5))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	require.ErrorContains(t, Process(cmd, time.Hour)(ctx), ErrKilled)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
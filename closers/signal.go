@@ -0,0 +1,32 @@
+package closers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ilKhr/closer"
+)
+
+// CloseChan returns a function closing ch, e.g. to tell internal
+// goroutines to stop. The channel is closed once, however many times
+// the function is called.
+func CloseChan[T any](ch chan<- T) closer.Func {
+	var once sync.Once
+
+	return func(ctx context.Context) error {
+		once.Do(func() {
+			close(ch)
+		})
+
+		return nil
+	}
+}
+
+// Cancel returns a function calling cancel, so goroutines stopped by
+// a context can be registered next to the real resources.
+func Cancel(cancel context.CancelFunc) closer.Func {
+	return func(ctx context.Context) error {
+		cancel()
+		return nil
+	}
+}
@@ -0,0 +1,26 @@
+package closers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CloseChan_HappyPath(t *testing.T) {
+	ch := make(chan struct{})
+	f := CloseChan(ch)
+
+	require.NoError(t, f(context.Background()))
+	require.NoError(t, f(context.Background()))
+
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func Test_Cancel_HappyPath(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.NoError(t, Cancel(cancel)(context.Background()))
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
@@ -0,0 +1,55 @@
+package closers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ilKhr/closer"
+)
+
+// SQLDB returns a function closing the database pool: it waits for the
+// connections in use to be returned to the pool as long as ctx allows,
+// then closes the pool. The connections still in use when ctx is done are
+// reported as abandoned together with the pool stats.
+func SQLDB(db *sql.DB) closer.Func {
+	return SQLDBWithStats(db, nil)
+}
+
+// SQLDBWithStats is like SQLDB, but it passes the pool stats taken before
+// closing to report, e.g. to log them, so DB shutdown issues are visible.
+func SQLDBWithStats(db *sql.DB, report func(sql.DBStats)) closer.Func {
+	return func(ctx context.Context) error {
+		op := "closers.SQLDB"
+
+		if report != nil {
+			report(db.Stats())
+		}
+
+		var errs []error
+
+		if n := drain(ctx, func() int { return db.Stats().InUse }); n > 0 {
+			stats := db.Stats()
+
+			errs = append(errs, fmt.Errorf("%d connections abandoned in use (open %d): %w", n, stats.OpenConnections, ctx.Err()))
+		}
+
+		// Close waits for the queries in progress, which may hang
+		closed := make(chan error, 1)
+
+		go func() {
+			closed <- db.Close()
+		}()
+
+		select {
+		case err := <-closed:
+			if err != nil {
+				errs = append(errs, fmt.Errorf("close: %w", err))
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("close abandoned: %w", ctx.Err()))
+		}
+
+		return join(op, errs)
+	}
+}
@@ -0,0 +1,65 @@
+package closers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver opens connections that do nothing.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func init() {
+	sql.Register("closers-fake", fakeDriver{})
+}
+
+func Test_SQLDB_HappyPath(t *testing.T) {
+	db, err := sql.Open("closers-fake", "")
+	require.NoError(t, err)
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = conn.Close()
+	}()
+
+	var stats sql.DBStats
+
+	require.NoError(t, SQLDBWithStats(db, func(s sql.DBStats) { stats = s })(context.Background()))
+	require.Equal(t, 1, stats.InUse)
+	require.ErrorContains(t, db.Ping(), "database is closed")
+}
+
+func Test_SQLDB_CancelWithCtxPath(t *testing.T) {
+	db, err := sql.Open("closers-fake", "")
+	require.NoError(t, err)
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err = SQLDB(db)(ctx)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "closers.SQLDB: 1 connections abandoned in use (open 1)")
+
+	_ = conn.Close()
+}
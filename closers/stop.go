@@ -0,0 +1,55 @@
+package closers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ilKhr/closer"
+)
+
+// Stopper is anything stopped by a plain Stop call.
+type Stopper interface {
+	Stop()
+}
+
+// WaitStopper is anything whose Stop returns a context done once the
+// in-flight work finishes, like the scheduler of robfig/cron.
+type WaitStopper interface {
+	Stop() context.Context
+}
+
+// Ticker returns a function stopping the ticker.
+func Ticker(t *time.Ticker) closer.Func {
+	return Stop(t)
+}
+
+// Timer returns a function stopping the timer.
+func Timer(t *time.Timer) closer.Func {
+	return func(ctx context.Context) error {
+		t.Stop()
+		return nil
+	}
+}
+
+// Stop returns a function calling Stop of s.
+func Stop(s Stopper) closer.Func {
+	return func(ctx context.Context) error {
+		s.Stop()
+		return nil
+	}
+}
+
+// StopWait returns a function calling Stop of s and waiting for the
+// in-flight work to finish as long as ctx allows, so periodic background
+// jobs are drained on shutdown.
+func StopWait(s WaitStopper) closer.Func {
+	return func(ctx context.Context) error {
+		select {
+		case <-s.Stop().Done():
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("%s: in-flight work not finished: %w", "closers.StopWait", ctx.Err())
+		}
+	}
+}
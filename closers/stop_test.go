@@ -0,0 +1,60 @@
+package closers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cron mimics a scheduler waiting for its running jobs on Stop.
+type cron struct {
+	jobs chan struct{}
+}
+
+func (c *cron) Stop() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-c.jobs
+		cancel()
+	}()
+
+	return ctx
+}
+
+func Test_Stop_HappyPath(t *testing.T) {
+	ticker := time.NewTicker(time.Millisecond)
+	timer := time.NewTimer(time.Hour)
+
+	require.NoError(t, Ticker(ticker)(context.Background()))
+	require.NoError(t, Timer(timer)(context.Background()))
+
+	// A stopped timer can't be stopped again
+	require.False(t, timer.Stop())
+}
+
+func Test_StopWait_HappyPath(t *testing.T) {
+	c := &cron{jobs: make(chan struct{})}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(c.jobs)
+	}()
+
+	require.NoError(t, StopWait(c)(context.Background()))
+}
+
+func Test_StopWait_CancelWithCtxPath(t *testing.T) {
+	c := &cron{jobs: make(chan struct{})}
+	defer close(c.jobs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := StopWait(c)(ctx)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualError(t, err, "closers.StopWait: in-flight work not finished: context deadline exceeded")
+}
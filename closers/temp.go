@@ -0,0 +1,62 @@
+package closers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ilKhr/closer"
+)
+
+// TempDir returns a function removing the directory at path with all its
+// contents, retrying a failing removal a few times while ctx allows.
+// Unlike a deferred os.RemoveAll, it also runs when the process is
+// terminated by a signal handled by the Closer.
+func TempDir(path string) closer.Func {
+	return func(ctx context.Context) error {
+		err := retry(ctx, func() error {
+			return os.RemoveAll(path)
+		})
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", "closers.TempDir", err)
+		}
+
+		return nil
+	}
+}
+
+// TempFiles returns a function removing the files matching the glob
+// pattern (see filepath.Glob), retrying a failing removal a few times
+// while ctx allows. The error reports every file that was not removed.
+func TempFiles(pattern string) closer.Func {
+	return func(ctx context.Context) error {
+		op := "closers.TempFiles"
+
+		paths, err := filepath.Glob(pattern)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		var errs []error
+
+		for _, path := range paths {
+			err := retry(ctx, func() error {
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+
+				return nil
+			})
+
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return join(op, errs)
+	}
+}
@@ -0,0 +1,55 @@
+package closers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TempDir_HappyPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tmp")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "file"), nil, 0o644))
+
+	require.NoError(t, TempDir(dir)(context.Background()))
+	require.NoDirExists(t, dir)
+
+	// Removing a missing directory is not an error
+	require.NoError(t, TempDir(dir)(context.Background()))
+}
+
+func Test_TempFiles_HappyPath(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a.tmp", "b.tmp", "keep.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	require.NoError(t, TempFiles(filepath.Join(dir, "*.tmp"))(context.Background()))
+
+	require.NoFileExists(t, filepath.Join(dir, "a.tmp"))
+	require.NoFileExists(t, filepath.Join(dir, "b.tmp"))
+	require.FileExists(t, filepath.Join(dir, "keep.txt"))
+}
+
+func Test_TempFiles_ErrorPath(t *testing.T) {
+	dir := t.TempDir()
+
+	// A non-empty directory can't be removed by os.Remove
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "x.tmp", "nested"), 0o755))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := TempFiles(filepath.Join(dir, "*.tmp"))(ctx)
+
+	require.ErrorContains(t, err, "closers.TempFiles: ")
+	require.ErrorContains(t, err, "x.tmp")
+	require.DirExists(t, filepath.Join(dir, "x.tmp"))
+
+	require.ErrorContains(t, TempFiles("[")(context.Background()), "closers.TempFiles: syntax error")
+}
@@ -0,0 +1,159 @@
+package closertest
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// AssertClosedInOrder checks that exactly the functions with the given
+// registration indexes were invoked, in that order.
+func AssertClosedInOrder(t testing.TB, r *Recorder, indexes ...int) bool {
+	t.Helper()
+
+	order := r.Order()
+
+	if !slices.Equal(order, indexes) {
+		t.Errorf("closertest: functions closed in order %v, expected %v", order, indexes)
+		return false
+	}
+
+	return true
+}
+
+// AssertAllClosed checks that every registered function was invoked exactly once.
+func AssertAllClosed(t testing.TB, r *Recorder) bool {
+	t.Helper()
+
+	counts := make([]int, r.Registered())
+
+	for _, i := range r.Order() {
+		counts[i]++
+	}
+
+	ok := true
+
+	for i, n := range counts {
+		if n != 1 {
+			t.Errorf("closertest: function %d closed %d times, expected once", i, n)
+			ok = false
+		}
+	}
+
+	return ok
+}
+
+// AssertSequence checks that exactly the functions with the given names
+// were invoked, in that order. The unnamed functions are named
+// "#<index>" after their registration index.
+func AssertSequence(t testing.TB, r *Recorder, names ...string) bool {
+	t.Helper()
+
+	var sequence []string
+
+	for _, c := range r.Calls() {
+		sequence = append(sequence, c.Name)
+	}
+
+	if !slices.Equal(sequence, names) {
+		t.Errorf("closertest: functions closed in sequence %q, expected %q", sequence, names)
+		return false
+	}
+
+	return true
+}
+
+// AssertOverlap checks that the named functions ran concurrently:
+// each of them started before the other returned.
+func AssertOverlap(t testing.TB, r *Recorder, a, b string) bool {
+	t.Helper()
+
+	ca, cb, ok := callPair(t, r, a, b)
+
+	if ok && !overlap(ca, cb) {
+		t.Errorf("closertest: functions %q and %q didn't run concurrently", a, b)
+		return false
+	}
+
+	return ok
+}
+
+// AssertNoOverlap checks that the named functions ran one after another.
+func AssertNoOverlap(t testing.TB, r *Recorder, a, b string) bool {
+	t.Helper()
+
+	ca, cb, ok := callPair(t, r, a, b)
+
+	if ok && overlap(ca, cb) {
+		t.Errorf("closertest: functions %q and %q ran concurrently", a, b)
+		return false
+	}
+
+	return ok
+}
+
+// AssertDeadline checks that the named function got a context with
+// a deadline at most d after it was invoked.
+func AssertDeadline(t testing.TB, r *Recorder, name string, d time.Duration) bool {
+	t.Helper()
+
+	c, ok := findCall(t, r, name)
+
+	if !ok {
+		return false
+	}
+
+	deadline, ok := c.Ctx.Deadline()
+
+	if !ok {
+		t.Errorf("closertest: function %q got no deadline, expected at most %v", name, d)
+		return false
+	}
+
+	if left := deadline.Sub(c.Start); left > d {
+		t.Errorf("closertest: function %q got a deadline in %v, expected at most %v", name, left, d)
+		return false
+	}
+
+	return true
+}
+
+// findCall returns the first invocation of the named function.
+func findCall(t testing.TB, r *Recorder, name string) (Call, bool) {
+	t.Helper()
+
+	for _, c := range r.Calls() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+
+	t.Errorf("closertest: function %q not closed", name)
+
+	return Call{}, false
+}
+
+// callPair returns the first invocations of the named functions, which
+// must have returned.
+func callPair(t testing.TB, r *Recorder, a, b string) (ca, cb Call, ok bool) {
+	t.Helper()
+
+	ca, okA := findCall(t, r, a)
+	cb, okB := findCall(t, r, b)
+
+	if !okA || !okB {
+		return ca, cb, false
+	}
+
+	if !ca.Done || !cb.Done {
+		t.Errorf("closertest: functions %q and %q must return first", a, b)
+		return ca, cb, false
+	}
+
+	return ca, cb, true
+}
+
+// overlap reports whether the invocations ran concurrently.
+func overlap(a, b Call) bool {
+	return a.Start.Before(b.End) && b.Start.Before(a.End)
+}
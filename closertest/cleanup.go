@@ -0,0 +1,61 @@
+package closertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ilKhr/closer"
+)
+
+// Terminator is a test resource terminated with a context, like
+// a container or a fixture server.
+type Terminator interface {
+	Terminate(ctx context.Context) error
+}
+
+// Terminate returns the function terminating the resource, so it can be
+// added to a Closer. Resources whose Terminate takes extra options, like
+// the testcontainers containers, are added with a closure instead.
+func Terminate(r Terminator) closer.Func {
+	return r.Terminate
+}
+
+// Cleanup closes cl within timeout (no limit if 0) once the test and its
+// subtests complete (see testing.TB.Cleanup) and fails the test if Close
+// fails. It lets integration tests share the shutdown orchestration of
+// the production code. A Closer already closed by the test is left as is.
+func Cleanup(tb testing.TB, cl *closer.Closer, timeout time.Duration) {
+	tb.Helper()
+
+	tb.Cleanup(func() {
+		if cl.Remaining() == 0 {
+			return
+		}
+
+		ctx := context.Background()
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		if err := cl.Close(ctx); err != nil {
+			tb.Errorf("closertest: cleanup failed: %v", err)
+		}
+	})
+}
+
+// NewCloser returns a Closer configured with opts and closed once the test
+// completes, see Cleanup.
+func NewCloser(tb testing.TB, timeout time.Duration, opts ...closer.Option) *closer.Closer {
+	tb.Helper()
+
+	cl := closer.New(opts...)
+
+	Cleanup(tb, cl, timeout)
+
+	return cl
+}
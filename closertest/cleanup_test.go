@@ -0,0 +1,80 @@
+package closertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ilKhr/closer"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTB records the cleanups and the errors of a test.
+type fakeTB struct {
+	testing.TB
+
+	cleanups []func()
+	errors   []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+func (tb *fakeTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+// container mimics a container terminated with a context.
+type container struct {
+	terminated bool
+	err        error
+}
+
+func (c *container) Terminate(ctx context.Context) error {
+	c.terminated = true
+	return c.err
+}
+
+func Test_NewCloser_HappyPath(t *testing.T) {
+	db := &container{}
+
+	t.Run("integration", func(t *testing.T) {
+		cl := NewCloser(t, time.Second)
+		cl.Add(Terminate(db))
+
+		require.False(t, db.terminated)
+	})
+
+	require.True(t, db.terminated)
+}
+
+func Test_Cleanup_ErrorPath(t *testing.T) {
+	tb := &fakeTB{}
+	db := &container{err: errors.New("still running")}
+
+	cl := NewCloser(tb, 0)
+	cl.Add(Terminate(db), closer.WithName("db"))
+
+	require.Len(t, tb.cleanups, 1)
+	tb.cleanups[0]()
+
+	require.True(t, db.terminated)
+	require.Equal(t, []string{"closertest: cleanup failed: closer.Close: db: still running"}, tb.errors)
+}
+
+func Test_Cleanup_ClosedPath(t *testing.T) {
+	tb := &fakeTB{}
+
+	cl := NewCloser(tb, 0)
+	cl.Add(Terminate(&container{}))
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	tb.cleanups[0]()
+	require.Empty(t, tb.errors)
+}
@@ -0,0 +1,104 @@
+package closertest
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ilKhr/closer"
+)
+
+// Clock is a fake closer.Clock whose time only moves with Advance.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*timer
+}
+
+// timer is a call scheduled on the fake clock.
+type timer struct {
+	clock *Clock
+	when  time.Time
+	f     func()
+}
+
+// NewClock creates a fake clock set to now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// AfterFunc schedules f to be called in its own goroutine
+// once the fake time reaches Now() + d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) closer.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// Advance moves the fake time forward by d and fires the due timers
+// in the order of their deadlines.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+
+	c.now = c.now.Add(d)
+
+	var due []*timer
+
+	c.timers = slices.DeleteFunc(c.timers, func(t *timer) bool {
+		if t.when.After(c.now) {
+			return false
+		}
+
+		due = append(due, t)
+
+		return true
+	})
+
+	c.mu.Unlock()
+
+	slices.SortStableFunc(due, func(a, b *timer) int {
+		return a.when.Compare(b.when)
+	})
+
+	for _, t := range due {
+		go t.f()
+	}
+}
+
+// Timers returns the number of pending timers.
+// Tests can poll it to wait until the code under test has scheduled
+// its timeouts before calling Advance.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+// Stop cancels the timer, it reports whether the timer was pending.
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	i := slices.Index(t.clock.timers, t)
+
+	if i < 0 {
+		return false
+	}
+
+	t.clock.timers = slices.Delete(t.clock.timers, i, i+1)
+
+	return true
+}
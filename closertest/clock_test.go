@@ -0,0 +1,56 @@
+package closertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ilKhr/closer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Clock_AdvancePath(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	fired := make(chan int, 2)
+
+	clock.AfterFunc(2*time.Second, func() { fired <- 2 })
+	stopped := clock.AfterFunc(time.Second, func() { fired <- 1 })
+
+	require.Equal(t, 2, clock.Timers())
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+
+	clock.Advance(time.Second)
+	require.Equal(t, 1, clock.Timers())
+
+	clock.Advance(time.Second)
+	require.Equal(t, 2, <-fired)
+	require.Equal(t, time.Unix(2, 0), clock.Now())
+}
+
+func Test_Clock_StageBudgetPath(t *testing.T) {
+	clock := NewClock(time.Now())
+	cl := closer.New(closer.WithClock(clock), closer.WithStageBudget())
+
+	cl.AddStage(0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cl.AddStage(1, func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Hour))
+	defer cancel()
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- cl.Close(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+	// The first stage gets half of the hour
+	clock.Advance(30 * time.Minute)
+
+	require.ErrorContains(t, <-errCh, context.DeadlineExceeded.Error())
+}
@@ -0,0 +1,203 @@
+// Package closertest provides utilities for testing code that registers
+// its shutdown functions with a closer.Closer.
+package closertest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/ilKhr/closer"
+)
+
+// Call describes one invocation of a registered function.
+type Call struct {
+	Index int             // Registration index of the function
+	Name  string          // Name of the function, "#<index>" if unnamed
+	Ctx   context.Context // Context passed to the function
+	Err   error           // Error returned by the function
+	Done  bool            // Whether the function has returned
+	Start time.Time       // When the function was invoked
+	End   time.Time       // When the function returned, zero until Done
+}
+
+// Recorder is a fake closer with the same API as closer.Closer.
+// It runs the registered functions through a real Closer and records
+// the registration order, the invocations and the contexts they got.
+// Errors and delays can be injected per registration index.
+type Recorder struct {
+	cl *closer.Closer
+
+	mu         sync.Mutex
+	registered int                   // Number of registered functions
+	calls      []Call                // Invocations in the order they started
+	errs       map[int]error         // Injected errors by registration index
+	delays     map[int]time.Duration // Injected delays by registration index
+}
+
+// New creates a Recorder backed by a Closer configured with opts.
+func New(opts ...closer.Option) *Recorder {
+	return &Recorder{
+		cl:     closer.New(opts...),
+		errs:   make(map[int]error),
+		delays: make(map[int]time.Duration),
+	}
+}
+
+var _ closer.Interface = (*Recorder)(nil)
+
+// Add adds a function to the list for closing, see closer.Closer.Add.
+// Like it, it panics with closer.ErrNilFunc if f is nil.
+func (r *Recorder) Add(f closer.Func, opts ...closer.FuncOption) closer.Handle {
+	if f == nil {
+		panic(nilFunc("closertest.Recorder.Add"))
+	}
+
+	return r.cl.Add(r.wrap(f), opts...)
+}
+
+// AddStage adds a function to the given stage, see closer.Closer.AddStage.
+// Like it, it panics with closer.ErrNilFunc if f is nil.
+func (r *Recorder) AddStage(stage int, f closer.Func, opts ...closer.FuncOption) closer.Handle {
+	if f == nil {
+		panic(nilFunc("closertest.Recorder.AddStage"))
+	}
+
+	return r.cl.AddStage(stage, r.wrap(f), opts...)
+}
+
+// nilFunc returns the message of the panic on a nil function, naming the
+// call site of the registration like closer.Closer.Add.
+func nilFunc(op string) string {
+	_, file, line, _ := runtime.Caller(2)
+
+	return fmt.Sprintf("%s: %v registered at %s:%d", op, closer.ErrNilFunc, file, line)
+}
+
+// Close closes all the functions, see closer.Closer.Close.
+func (r *Recorder) Close(ctx context.Context) error {
+	return r.cl.Close(ctx)
+}
+
+// CloseOne closes one function, see closer.Closer.CloseOne.
+func (r *Recorder) CloseOne(ctx context.Context) error {
+	return r.cl.CloseOne(ctx)
+}
+
+// Size returns the number of added functions to close.
+func (r *Recorder) Size() int {
+	return r.cl.Size()
+}
+
+// Remaining returns the number of functions not closed yet,
+// see closer.Closer.Remaining.
+func (r *Recorder) Remaining() int {
+	return r.cl.Remaining()
+}
+
+// Err returns the error of the last finished Close, see closer.Closer.Err.
+func (r *Recorder) Err() error {
+	return r.cl.Err()
+}
+
+// InjectError makes the function with the given registration index
+// return err instead of its own result.
+func (r *Recorder) InjectError(index int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs[index] = err
+}
+
+// InjectDelay makes the function with the given registration index
+// wait for d (or until its context is done) before it runs.
+func (r *Recorder) InjectDelay(index int, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delays[index] = d
+}
+
+// Registered returns the number of functions registered so far.
+func (r *Recorder) Registered() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.registered
+}
+
+// Calls returns the recorded invocations in the order they started.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]Call, len(r.calls))
+	copy(calls, r.calls)
+
+	return calls
+}
+
+// Order returns the registration indexes of the invoked functions
+// in the order they started.
+func (r *Recorder) Order() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order := make([]int, 0, len(r.calls))
+
+	for _, c := range r.calls {
+		order = append(order, c.Index)
+	}
+
+	return order
+}
+
+// wrap returns a function recording the invocations of f.
+func (r *Recorder) wrap(f closer.Func) closer.Func {
+	r.mu.Lock()
+	index := r.registered
+	r.registered++
+	r.mu.Unlock()
+
+	return func(ctx context.Context) error {
+		r.mu.Lock()
+		call := len(r.calls)
+		name, _ := closer.NameFromContext(ctx)
+		r.calls = append(r.calls, Call{Index: index, Name: name, Ctx: ctx, Start: time.Now()})
+		delay := r.delays[index]
+		r.mu.Unlock()
+
+		err := run(ctx, f, delay)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if injected, ok := r.errs[index]; ok {
+			err = injected
+		}
+
+		r.calls[call].Err = err
+		r.calls[call].Done = true
+		r.calls[call].End = time.Now()
+
+		return err
+	}
+}
+
+// run waits for the delay and calls f.
+func run(ctx context.Context, f closer.Func, delay time.Duration) error {
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return f(ctx)
+}
@@ -0,0 +1,168 @@
+package closertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ilKhr/closer"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+// nop is a function to close returning nil.
+func nop(ctx context.Context) error {
+	return nil
+}
+
+func Test_Recorder_HappyPath(t *testing.T) {
+	rec := New(closer.WithSynchronousExecution())
+
+	rec.AddStage(1, nop)
+	rec.Add(nop)
+	rec.Add(func(ctx context.Context) error { return nil })
+
+	require.Equal(t, 3, rec.Size())
+	require.Equal(t, 3, rec.Registered())
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	require.NoError(t, rec.Close(ctx))
+
+	AssertClosedInOrder(t, rec, 1, 2, 0)
+	AssertAllClosed(t, rec)
+
+	for _, call := range rec.Calls() {
+		require.True(t, call.Done)
+		require.Equal(t, "value", call.Ctx.Value(ctxKey{}))
+	}
+}
+
+func Test_Recorder_InjectErrorPath(t *testing.T) {
+	rec := New()
+	errInjected := errors.New("injected")
+
+	rec.Add(nop)
+	rec.Add(nop)
+	rec.InjectError(1, errInjected)
+
+	require.NoError(t, rec.CloseOne(context.Background()))
+	require.ErrorIs(t, rec.CloseOne(context.Background()), errInjected)
+
+	calls := rec.Calls()
+	require.Len(t, calls, 2)
+	require.NoError(t, calls[0].Err)
+	require.ErrorIs(t, calls[1].Err, errInjected)
+}
+
+func Test_Recorder_InjectDelayPath(t *testing.T) {
+	rec := New()
+
+	rec.Add(nop)
+	rec.InjectDelay(0, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := rec.Close(ctx)
+
+	require.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	require.ErrorIs(t, rec.Calls()[0].Err, context.DeadlineExceeded)
+}
+
+func Test_AssertClosedInOrder_MismatchPath(t *testing.T) {
+	rec := New(closer.WithSynchronousExecution())
+
+	rec.Add(nop)
+	rec.Add(nop)
+
+	require.NoError(t, rec.Close(context.Background()))
+
+	mock := &testing.T{}
+	require.False(t, AssertClosedInOrder(mock, rec, 1, 0))
+	require.True(t, AssertClosedInOrder(t, rec, 0, 1))
+}
+
+func Test_AssertSequence_HappyPath(t *testing.T) {
+	rec := New()
+	sleep := func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+
+	rec.AddStage(0, sleep, closer.WithName("http"), closer.WithTimeout(time.Second))
+	rec.AddStage(0, sleep, closer.WithName("grpc"))
+	rec.AddStage(1, nop, closer.WithName("workers"))
+	rec.AddStage(2, nop)
+
+	require.NoError(t, rec.Close(context.Background()))
+
+	AssertSequence(t, rec, "http", "grpc", "workers", "#3")
+	AssertOverlap(t, rec, "http", "grpc")
+	AssertNoOverlap(t, rec, "grpc", "workers")
+	AssertDeadline(t, rec, "http", time.Second)
+}
+
+func Test_AssertSequence_MismatchPath(t *testing.T) {
+	rec := New(closer.WithSynchronousExecution())
+
+	rec.Add(nop, closer.WithName("http"))
+	rec.Add(nop, closer.WithName("db"))
+
+	require.NoError(t, rec.Close(context.Background()))
+
+	tb := &fakeTB{}
+
+	require.False(t, AssertSequence(tb, rec, "db", "http"))
+	require.False(t, AssertOverlap(tb, rec, "http", "db"))
+	require.False(t, AssertDeadline(tb, rec, "http", time.Second))
+	require.False(t, AssertNoOverlap(tb, rec, "http", "cache"))
+	require.Len(t, tb.errors, 4)
+	require.Equal(t, `closertest: functions closed in sequence ["http" "db"], expected ["db" "http"]`, tb.errors[0])
+}
+
+// shutdown is the code under test, accepting any closer.
+func shutdown(ctx context.Context, cl closer.Interface) error {
+	if cl.Remaining() == 0 {
+		return nil
+	}
+
+	return cl.Close(ctx)
+}
+
+func Test_Recorder_InterfacePath(t *testing.T) {
+	rec := New()
+
+	require.NotNil(t, rec.Add(nop, closer.WithName("db")))
+	rec.InjectError(0, errors.New("busy"))
+
+	require.Error(t, shutdown(context.Background(), rec))
+	require.Zero(t, rec.Remaining())
+	require.ErrorContains(t, rec.Err(), "busy")
+	require.NoError(t, shutdown(context.Background(), rec))
+}
+
+func Test_Recorder_NilFuncPath(t *testing.T) {
+	rec := New()
+
+	require.PanicsWithValue(t, fmt.Sprintf("closertest.Recorder.Add: %v registered at %s", closer.ErrNilFunc, lineAfter(t, 1)), func() {
+		rec.Add(nil)
+	})
+	require.Panics(t, func() {
+		rec.AddStage(1, nil)
+	})
+	require.Zero(t, rec.Registered())
+}
+
+// lineAfter returns the file and line n lines after the call.
+func lineAfter(t *testing.T, n int) string {
+	t.Helper()
+
+	_, file, line, _ := runtime.Caller(1)
+
+	return fmt.Sprintf("%s:%d", file, line+n)
+}
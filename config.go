@@ -0,0 +1,134 @@
+package closer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrInvalidConfig is returned by ApplyConfig for a negative setting.
+const ErrInvalidConfig = "invalid config"
+
+// Config tunes the functions by name, so operators can adjust the shutdown
+// per environment without recompiling, see Closer.ApplyConfig. It decodes
+// from JSON with LoadConfig, or from YAML with the usual libraries:
+//
+//	{
+//		"stages": {"workers": {"timeout": "10s"}},
+//		"funcs": {"db": {"timeout": "3s", "retries": 2}, "metrics": {"skip": true}}
+//	}
+type Config struct {
+	// Stages tunes every function of the named stages, see Closer.Stage.
+	Stages map[string]FuncConfig `json:"stages,omitempty" yaml:"stages,omitempty"`
+	// Funcs tunes the named functions, overriding their stage settings.
+	Funcs map[string]FuncConfig `json:"funcs,omitempty" yaml:"funcs,omitempty"`
+}
+
+// FuncConfig is the tuning of a function or a stage. The zero settings
+// leave the registration settings as they are.
+type FuncConfig struct {
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"` // See WithTimeout
+	Retries *int     `json:"retries,omitempty" yaml:"retries,omitempty"` // See WithRetry
+	Skip    bool     `json:"skip,omitempty" yaml:"skip,omitempty"`       // See Closer.Disable
+}
+
+// Duration is a time.Duration written as a string like "1m30s" in configs.
+type Duration time.Duration
+
+// MarshalText formats the duration like time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText parses the duration with time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// LoadConfig decodes a JSON Config from r, rejecting unknown fields.
+func LoadConfig(r io.Reader) (Config, error) {
+	op := "closer.LoadConfig"
+
+	var cfg Config
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cfg, nil
+}
+
+// ApplyConfig applies cfg to the functions not closed yet and keeps it for
+// the functions added later, replacing the previous config; the settings
+// applied by the previous config stay. Names without a function are not
+// an error, as the function may be added later.
+func (c *Closer) ApplyConfig(cfg Config) error {
+	op := "closer.ApplyConfig"
+
+	for _, section := range []map[string]FuncConfig{cfg.Stages, cfg.Funcs} {
+		for name, fc := range section {
+			if fc.Timeout < 0 || fc.Retries != nil && *fc.Retries < 0 {
+				return fmt.Errorf("%s: %v: negative setting of %q", op, ErrInvalidConfig, name)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.config = &cfg
+
+	for i := c.i; i < len(c.funcs); i++ {
+		if !c.funcs[i].taken {
+			c.configure(&c.funcs[i])
+		}
+	}
+
+	return nil
+}
+
+// configure applies the config to the entry, the stage settings first.
+// It must be called under the mutex.
+func (c *Closer) configure(e *entry) {
+	if c.config == nil {
+		return
+	}
+
+	if name := c.stageName(e.stage); name != "" {
+		if fc, ok := c.config.Stages[name]; ok {
+			fc.apply(e)
+		}
+	}
+
+	if e.name != "" {
+		if fc, ok := c.config.Funcs[e.name]; ok {
+			fc.apply(e)
+		}
+	}
+}
+
+func (fc FuncConfig) apply(e *entry) {
+	if fc.Timeout > 0 {
+		e.timeout = time.Duration(fc.Timeout)
+	}
+
+	if fc.Retries != nil {
+		e.retries = *fc.Retries
+	}
+
+	if fc.Skip {
+		e.disabled = true
+	}
+}
@@ -0,0 +1,72 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApplyConfig_HappyPath(t *testing.T) {
+	cfg, err := LoadConfig(strings.NewReader(`{
+		"stages": {"storage": {"timeout": "1h", "retries": 1}},
+		"funcs": {"db": {"timeout": "20ms"}, "metrics": {"skip": true}}
+	}`))
+
+	require.NoError(t, err)
+
+	var (
+		cl            Closer
+		dbAttempts    int
+		cacheAttempts int
+		cacheDeadline time.Duration
+	)
+
+	cl.Stage("storage").Add(func(ctx context.Context) error {
+		dbAttempts++
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, WithName("db"))
+
+	require.NoError(t, cl.ApplyConfig(cfg))
+
+	// Added after the config, the function is tuned as well
+	cl.Stage("storage").Add(func(ctx context.Context) error {
+		cacheAttempts++
+		deadline, _ := ctx.Deadline()
+		cacheDeadline = time.Until(deadline)
+
+		return errors.New("busy")
+	}, WithName("cache"))
+
+	cl.Add(func(ctx context.Context) error {
+		t.Fatal("skipped function closed")
+		return nil
+	}, WithName("metrics"))
+
+	err = cl.Close(context.Background())
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 2, dbAttempts)
+	require.Equal(t, 2, cacheAttempts)
+	require.Greater(t, cacheDeadline, time.Minute)
+}
+
+func Test_ApplyConfig_ErrorPath(t *testing.T) {
+	_, err := LoadConfig(strings.NewReader(`{"funcs": {"db": {"timeout": "soon"}}}`))
+	require.Error(t, err)
+
+	_, err = LoadConfig(strings.NewReader(`{"func": {}}`))
+	require.Error(t, err)
+
+	var cl Closer
+
+	retries := -1
+
+	err = cl.ApplyConfig(Config{Funcs: map[string]FuncConfig{"db": {Retries: &retries}}})
+	require.ErrorContains(t, err, ErrInvalidConfig)
+}
@@ -0,0 +1,96 @@
+package closer
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"slices"
+)
+
+// Bind arranges for Close to be called once ctx is cancelled.
+// The closer functions receive a context that keeps the values of ctx
+// but is not cancelled with it.
+// The returned stop function unbinds the closer from ctx; it reports
+// whether the call stopped Close from being run.
+func (c *Closer) Bind(ctx context.Context) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		_ = c.Close(context.WithoutCancel(ctx))
+	})
+}
+
+// WithSignals returns a copy of parent that is cancelled when one of the
+// listed signals arrives (see signal.NotifyContext), and a Closer bound to
+// that context: Close runs as soon as the context is cancelled.
+// If no signals are provided, all incoming signals are relayed.
+func WithSignals(parent context.Context, sigs ...os.Signal) (ctx context.Context, cl *Closer) {
+	ctx, stop := signal.NotifyContext(parent, sigs...)
+
+	cl = &Closer{}
+
+	context.AfterFunc(ctx, func() {
+		// Restore the default signal behavior so a second signal
+		// terminates the process while closing.
+		stop()
+
+		_ = cl.Close(context.WithoutCancel(ctx))
+	})
+
+	return ctx, cl
+}
+
+// closingKey marks the context passed to the functions of a Closer.
+type closingKey struct {
+	c *Closer
+}
+
+// markClosing returns a copy of ctx marked as passed to the functions of c.
+func (c *Closer) markClosing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, closingKey{c: c}, struct{}{})
+}
+
+// reentrant reports whether ctx comes from a function of c, that is
+// whether the function calls Close or CloseOne of its own Closer.
+// The detection relies on the function passing its context along.
+func (c *Closer) reentrant(ctx context.Context) bool {
+	return ctx.Value(closingKey{c: c}) != nil
+}
+
+// metaKey keys the registration metadata in the context of a function.
+type metaKey struct{}
+
+// FuncMeta is the registration metadata of a function passed to it
+// through its context, see WithContextDecorator.
+type FuncMeta struct {
+	Name     string   // Name of the function, "#<index>" for an unnamed one
+	Tags     []string // Tags of the function, see WithTags
+	Stage    int      // Stage of the function, see WithStage
+	Priority int      // Priority of the function, see WithPriority
+	Attempt  int      // Number of the attempt to close the function, starting from 1
+}
+
+// NameFromContext returns the name of the function the context was passed
+// to by a Closer, "#<index>" for an unnamed one, so shared generic close
+// functions can log which resource they're tearing down. It reports
+// whether ctx comes from a Closer.
+func NameFromContext(ctx context.Context) (string, bool) {
+	meta, ok := ctx.Value(metaKey{}).(FuncMeta)
+
+	return meta.Name, ok
+}
+
+// TagsFromContext returns the tags of the function the context was passed
+// to by a Closer, see WithTags.
+func TagsFromContext(ctx context.Context) []string {
+	meta, _ := ctx.Value(metaKey{}).(FuncMeta)
+
+	return slices.Clone(meta.Tags)
+}
+
+// AttemptFromContext returns the number of the attempt to close the
+// function the context was passed to by a Closer, starting from 1,
+// see WithRetry. It returns 0 if ctx doesn't come from a Closer.
+func AttemptFromContext(ctx context.Context) int {
+	meta, _ := ctx.Value(metaKey{}).(FuncMeta)
+
+	return meta.Attempt
+}
@@ -0,0 +1,256 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Bind_HappyPath(t *testing.T) {
+	var cl Closer
+	mocks := []*mockCloseFunc{{}, {}, {}}
+
+	for _, mcf := range mocks {
+		cl.Add(mcf.close)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.Bind(ctx)
+	cancel()
+
+	for _, mcf := range mocks {
+		require.Eventually(t, func() bool {
+			mcf.mu.Lock()
+			defer mcf.mu.Unlock()
+
+			return mcf.calledCount == 1
+		}, time.Second, time.Millisecond)
+	}
+}
+
+func Test_Bind_StopPath(t *testing.T) {
+	var cl Closer
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stop := cl.Bind(ctx)
+	require.True(t, stop())
+
+	cancel()
+
+	err := cl.CloseOne(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, mcf.calledCount)
+}
+
+func Test_WithSignals_HappyPath(t *testing.T) {
+	ctx, cl := WithSignals(context.Background(), syscall.SIGUSR1)
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not cancelled by the signal")
+	}
+
+	require.Eventually(t, func() bool {
+		mcf.mu.Lock()
+		defer mcf.mu.Unlock()
+
+		return mcf.calledCount == 1
+	}, time.Second, time.Millisecond)
+}
+
+func Test_WithSignals_ParentCancelPath(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, cl := WithSignals(parent, syscall.SIGUSR2)
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+	cancel()
+
+	<-ctx.Done()
+
+	require.Eventually(t, func() bool {
+		mcf.mu.Lock()
+		defer mcf.mu.Unlock()
+
+		return mcf.calledCount == 1
+	}, time.Second, time.Millisecond)
+}
+
+func Test_Close_ReentrantPath(t *testing.T) {
+	var (
+		cl    Closer
+		other Closer
+	)
+
+	mcf := &mockCloseFunc{}
+	other.Add(mcf.close)
+
+	var errClose, errCloseOne, errOther error
+
+	cl.Add(func(ctx context.Context) error {
+		errClose = cl.Close(ctx)
+		errCloseOne = cl.CloseOne(ctx)
+
+		// Closing another closer from a close function is fine
+		errOther = other.Close(ctx)
+
+		return nil
+	})
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.CloseOne(context.Background()))
+
+	require.ErrorContains(t, errClose, ErrReentrantClose)
+	require.ErrorContains(t, errCloseOne, ErrReentrantClose)
+	require.NoError(t, errOther)
+	require.Equal(t, 1, mcf.calledCount)
+
+	// The reentrant calls didn't take the remaining function
+	require.Equal(t, 1, cl.Remaining())
+}
+
+func Test_WaitClosed_HappyPath(t *testing.T) {
+	var cl Closer
+
+	release := make(chan struct{})
+
+	cl.Add(func(ctx context.Context) error {
+		<-release
+		return errors.New("failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cl.Bind(ctx)
+
+	waitErr := make(chan error, 1)
+
+	go func() {
+		waitErr <- cl.WaitClosed(context.Background())
+	}()
+
+	cancel()
+
+	select {
+	case <-waitErr:
+		t.Fatal("WaitClosed returned before Close finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+
+	require.ErrorContains(t, <-waitErr, "failed")
+
+	// Close has finished, so WaitClosed returns at once
+	require.ErrorContains(t, cl.WaitClosed(context.Background()), "failed")
+}
+
+func Test_WaitClosed_NothingLeftPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(cl *Closer)
+	}{
+		{name: "empty", prepare: func(cl *Closer) {}},
+		{name: "all_consumed", prepare: func(cl *Closer) {
+			cl.Add(func(ctx context.Context) error { return nil })
+			h := cl.Add(func(ctx context.Context) error { return nil })
+
+			require.NoError(t, cl.CloseOne(context.Background()))
+			require.NoError(t, h.Close(context.Background()))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cl Closer
+
+			tt.prepare(&cl)
+
+			waitErr := make(chan error, 1)
+
+			go func() {
+				waitErr <- cl.WaitClosed(context.Background())
+			}()
+
+			require.ErrorContains(t, cl.Close(context.Background()), ErrAllServicesClosed)
+
+			select {
+			case err := <-waitErr:
+				require.NoError(t, err)
+			case <-time.After(time.Second):
+				t.Fatal("WaitClosed didn't return")
+			}
+
+			require.True(t, cl.Finished())
+		})
+	}
+}
+
+func Test_WaitClosed_CancelWithCtxPath(t *testing.T) {
+	var cl Closer
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, cl.WaitClosed(ctx), context.DeadlineExceeded)
+}
+
+func Test_FromContext_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	type meta struct {
+		name    string
+		ok      bool
+		tags    []string
+		attempt int
+	}
+
+	var metas []meta
+
+	record := func(ctx context.Context) {
+		name, ok := NameFromContext(ctx)
+		metas = append(metas, meta{name: name, ok: ok, tags: TagsFromContext(ctx), attempt: AttemptFromContext(ctx)})
+	}
+
+	cl.Add(func(ctx context.Context) error {
+		record(ctx)
+
+		if AttemptFromContext(ctx) < 2 {
+			return errors.New("busy")
+		}
+
+		return nil
+	}, WithName("db"), WithTags("storage"), WithRetry(3))
+
+	cl.Add(func(ctx context.Context) error {
+		record(ctx)
+		return nil
+	})
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []meta{
+		{name: "db", ok: true, tags: []string{"storage"}, attempt: 1},
+		{name: "db", ok: true, tags: []string{"storage"}, attempt: 2},
+		{name: "#1", ok: true, attempt: 1},
+	}, metas)
+
+	name, ok := NameFromContext(context.Background())
+
+	require.Empty(t, name)
+	require.False(t, ok)
+	require.Zero(t, AttemptFromContext(context.Background()))
+}
@@ -0,0 +1,70 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// DeadlineError is added to the error of Close when its deadline is
+// exceeded while functions are still running, naming them, so it is
+// clear what to fix. errors.Is matches it with context.DeadlineExceeded.
+type DeadlineError struct {
+	Running []string // Names of the functions running at the deadline, in the registration order
+}
+
+func (e *DeadlineError) Error() string {
+	return "deadline exceeded while running\x20" + strings.Join(e.Running, ",\x20")
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *DeadlineError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// watchRunning records the functions running once the deadline of ctx is
+// exceeded. The returned function stops the watch and returns them.
+func (c *Closer) watchRunning(ctx context.Context) (stop func() []string) {
+	var (
+		running []string
+		done    = make(chan struct{})
+	)
+
+	stopWatch := context.AfterFunc(ctx, func() {
+		defer close(done)
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			running = c.runningNames()
+		}
+	})
+
+	return func() []string {
+		if !stopWatch() {
+			<-done
+		}
+
+		return running
+	}
+}
+
+// runningNames returns the names of the functions running, in the
+// registration order.
+func (c *Closer) runningNames() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var names []string
+
+	for _, e := range c.funcs {
+		if e.out != nil && e.out.state.Load() == outcomeRunning {
+			names = append(names, e.label())
+		}
+	}
+
+	return names
+}
+
+// isDeadline reports whether err is a deadline being exceeded.
+func isDeadline(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
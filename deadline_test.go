@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeadlineError_HappyPath(t *testing.T) {
+	var cl Closer
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("cache"))
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		// Slow to notice the deadline
+		time.Sleep(20 * time.Millisecond)
+
+		return ctx.Err()
+	}, WithName("db"))
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := cl.Close(ctx)
+
+	var dErr *DeadlineError
+
+	require.ErrorAs(t, err, &dErr)
+	require.Equal(t, []string{"db", "#2"}, dErr.Running)
+	require.ErrorIs(t, dErr, context.DeadlineExceeded)
+	require.EqualError(t, err, "closer.Close: db: context deadline exceeded; deadline exceeded while running db, #2")
+}
+
+func Test_DeadlineError_FuncTimeoutPath(t *testing.T) {
+	var cl Closer
+
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	err := cl.Close(context.Background())
+
+	// The Close deadline is not exceeded, the unnamed function is named
+	require.False(t, errors.As(err, new(*DeadlineError)))
+	require.EqualError(t, err, "closer.Close: #0: context deadline exceeded")
+}
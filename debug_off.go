@@ -0,0 +1,6 @@
+//go:build !closerdebug
+
+package closer
+
+// debug reports whether the package is built with the closerdebug tag.
+const debug = false
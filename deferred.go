@@ -0,0 +1,42 @@
+package closer
+
+import "context"
+
+// Deferred is a function-local cleanup scope used like defer: its
+// functions are closed one by one in the reverse order of registration.
+// If it is abandoned without Close, its functions are swept by a Close of
+// the parent Closer, like the ones of a Scope.
+//
+//	s := cl.Deferred(ctx)
+//	defer s.Close()
+//
+//	s.Add(file.Close)
+type Deferred struct {
+	ctx   context.Context
+	scope *Closer
+}
+
+// Deferred returns a Deferred scope of the Closer closed with ctx.
+func (c *Closer) Deferred(ctx context.Context) *Deferred {
+	scope := c.Scope(WithSynchronousExecution())
+	scope.lifo = true
+
+	return &Deferred{ctx: ctx, scope: scope}
+}
+
+// Add adds a function to the scope, see Closer.Add.
+func (d *Deferred) Add(f Func, opts ...FuncOption) Handle {
+	return mustAdd(d.scope.add("closer.Deferred.Add", f, opts))
+}
+
+// Close closes the functions of the scope in the reverse order of
+// registration and detaches the scope from the parent if it succeeded.
+// Closing an empty scope is not an error.
+func (d *Deferred) Close() error {
+	if d.scope.Remaining() == 0 {
+		d.scope.detach()
+		return nil
+	}
+
+	return d.scope.Close(d.ctx)
+}
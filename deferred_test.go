@@ -0,0 +1,57 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Deferred_HappyPath(t *testing.T) {
+	var (
+		cl    Closer
+		order []string
+	)
+
+	func() {
+		s := cl.Deferred(context.Background())
+		defer func() { require.NoError(t, s.Close()) }()
+
+		for _, name := range []string{"file", "conn", "tx"} {
+			s.Add(func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+	}()
+
+	require.Equal(t, []string{"tx", "conn", "file"}, order)
+	require.Empty(t, cl.scopes)
+
+	// An empty scope detaches as well
+	require.NoError(t, cl.Deferred(context.Background()).Close())
+	require.Empty(t, cl.scopes)
+}
+
+func Test_Deferred_AbandonedPath(t *testing.T) {
+	var (
+		cl    Closer
+		order []string
+	)
+
+	s := cl.Deferred(context.Background())
+
+	s.Add(func(ctx context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	s.Add(func(ctx context.Context) error {
+		order = append(order, "second")
+		return errors.New("failed")
+	})
+
+	// The abandoned scope escalates to the parent
+	require.EqualError(t, cl.Close(context.Background()), "closer.Close: scope: closer.Close: failed")
+	require.Equal(t, []string{"second", "first"}, order)
+}
@@ -0,0 +1,51 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"runtime/pprof"
+)
+
+// watchDeadline dumps the goroutines as soon as the deadline of ctx is
+// exceeded, if enabled, while the stuck functions are still running.
+// The returned function stops the watch, waiting for a dump in progress,
+// so the dump is complete before Close returns.
+func (c *Closer) watchDeadline(ctx context.Context, op string) (stop func()) {
+	if !c.dump {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+
+	stopDump := context.AfterFunc(ctx, func() {
+		defer close(done)
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.dumpGoroutines(op)
+		}
+	})
+
+	return func() {
+		if !stopDump() {
+			<-done
+		}
+	}
+}
+
+// dumpGoroutines writes the stacks of all the goroutines to the dump
+// writer, or logs them if there is none.
+func (c *Closer) dumpGoroutines(op string) {
+	var buf bytes.Buffer
+
+	// Debug level 2 gives the panic-like format with the wait reasons
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 2)
+
+	if c.dumpWriter != nil {
+		_, _ = io.Copy(c.dumpWriter, &buf)
+		return
+	}
+
+	c.log(LevelError, "closer: shutdown deadline exceeded", "op", op, "goroutines", buf.String())
+}
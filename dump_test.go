@@ -0,0 +1,94 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GoroutineDump_HappyPath(t *testing.T) {
+	var buf bytes.Buffer
+
+	cl := New(WithGoroutineDump(&buf))
+
+	cl.Add(func(ctx context.Context) error {
+		return nil
+	})
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Empty(t, buf.String())
+}
+
+func Test_GoroutineDump_DeadlinePath(t *testing.T) {
+	var buf bytes.Buffer
+
+	cl := New(WithGoroutineDump(&buf))
+
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, cl.Close(ctx))
+	require.Contains(t, buf.String(), "goroutine ")
+	require.Contains(t, buf.String(), "Test_GoroutineDump_DeadlinePath")
+}
+
+func Test_GoroutineDump_CancelWithCtxPath(t *testing.T) {
+	var buf bytes.Buffer
+
+	cl := New(WithGoroutineDump(&buf))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.Add(func(ctx context.Context) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.Error(t, cl.Close(ctx))
+	require.Empty(t, buf.String())
+}
+
+func Test_GoroutineDump_LoggerPath(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		dump string
+	)
+
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		for i := 0; i+1 < len(kv); i += 2 {
+			if kv[i] == "goroutines" {
+				dump = kv[i+1].(string)
+			}
+		}
+	})
+
+	cl := New(WithGoroutineDump(nil), WithLogger(logger))
+
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, cl.Close(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Contains(t, dump, "goroutine ")
+}
@@ -0,0 +1,220 @@
+package closer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// baselineFactor is how many times its baseline a function may take
+// before it is logged as slow.
+const baselineFactor = 2
+
+// DurationStore keeps the durations the named functions took in the
+// previous shutdowns, see WithDurationStore. It must be safe for
+// concurrent use.
+type DurationStore interface {
+	// Load returns the duration stored for the function, if any.
+	Load(name string) (time.Duration, bool)
+	// Store stores the duration of the function.
+	Store(name string, d time.Duration) error
+}
+
+// DurationFlusher is implemented by the DurationStores persisting the
+// durations in batches, e.g. FileStore: Close flushes the store once after
+// its last stage (CloseOne after its function), rather than persisting
+// every duration as it is stored.
+type DurationFlusher interface {
+	// Flush persists the durations stored since the last flush.
+	Flush() error
+}
+
+// MemoryStore is a DurationStore keeping the durations in memory,
+// e.g. for a Closer reused across reloads or tests.
+type MemoryStore struct {
+	mu        sync.Mutex
+	durations map[string]time.Duration
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{durations: make(map[string]time.Duration)}
+}
+
+// Load returns the duration stored for the function, if any.
+func (s *MemoryStore) Load(name string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.durations[name]
+
+	return d, ok
+}
+
+// Store stores the duration of the function.
+func (s *MemoryStore) Store(name string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.durations[name] = d
+
+	return nil
+}
+
+// FileStore is a DurationStore persisting the durations to a JSON file,
+// so they survive the process. The file is read on the first Load or
+// Store; Store keeps the durations in memory and Flush rewrites the file
+// atomically, once per Close when used by a Closer.
+type FileStore struct {
+	path string
+
+	mu        sync.Mutex
+	durations map[string]time.Duration // Loaded durations, nil until read
+	dirty     bool                     // Whether durations were stored since the last flush
+}
+
+// NewFileStore creates a FileStore persisting to the file at path.
+// A missing file stands for an empty store.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load returns the duration stored for the function, if any.
+// An unreadable file stands for an empty store.
+func (s *FileStore) Load(name string) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.read()
+
+	d, ok := s.durations[name]
+
+	return d, ok
+}
+
+// Store stores the duration of the function, see Flush to persist it.
+func (s *FileStore) Store(name string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); err != nil {
+		return err
+	}
+
+	s.durations[name] = d
+	s.dirty = true
+
+	return nil
+}
+
+// Flush rewrites the file with the durations if any was stored since
+// the last flush.
+func (s *FileStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(s.durations)
+
+	if err != nil {
+		return err
+	}
+
+	// Write a temporary file and rename it, so the file is never torn
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	s.dirty = false
+
+	return nil
+}
+
+// read loads the file once. It must be called under the mutex.
+func (s *FileStore) read() error {
+	if s.durations != nil {
+		return nil
+	}
+
+	s.durations = make(map[string]time.Duration)
+
+	data, err := os.ReadFile(s.path)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &s.durations)
+}
+
+// baseline returns the duration the named function took in the previous
+// shutdowns, if known.
+func (c *Closer) baseline(e entry) (time.Duration, bool) {
+	if c.store == nil || e.name == "" {
+		return 0, false
+	}
+
+	return c.store.Load(e.name)
+}
+
+// recordDuration warns if the function ran much slower than its baseline
+// and stores its duration as the new baseline if it closed successfully.
+func (c *Closer) recordDuration(e entry, res Result) {
+	if c.store == nil || e.name == "" {
+		return
+	}
+
+	if d, ok := c.store.Load(e.name); ok && res.Duration > baselineFactor*d {
+		c.log(LevelWarn, "closer: function slower than its baseline", "name", e.name, "duration", res.Duration, "baseline", d)
+	}
+
+	if res.Status != StatusClosed {
+		return
+	}
+
+	if err := c.store.Store(e.name, res.Duration); err != nil {
+		c.log(LevelError, "closer: storing the duration failed", "name", e.name, "error", err)
+	}
+}
+
+// flushDurations persists the durations stored by the functions closed,
+// if the store persists them in batches, see DurationFlusher.
+func (c *Closer) flushDurations() {
+	f, ok := c.store.(DurationFlusher)
+
+	if !ok {
+		return
+	}
+
+	if err := f.Flush(); err != nil {
+		c.log(LevelError, "closer: flushing the durations failed", "error", err)
+	}
+}
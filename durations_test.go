@@ -0,0 +1,148 @@
+package closer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DurationStore_HappyPath(t *testing.T) {
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Store("flush", 300*time.Millisecond))
+	require.NoError(t, store.Store("db", 100*time.Millisecond))
+
+	cl := New(WithSynchronousExecution(), WithFuncBudget(), WithDurationStore(store))
+
+	var deadline time.Time
+
+	cl.Add(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}, WithName("flush"))
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("db"))
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, cl.Close(ctx))
+
+	// The unnamed function gets the mean baseline: 300 of 300+100+200
+	require.InDelta(t, 2500*time.Millisecond, deadline.Sub(start), float64(100*time.Millisecond))
+
+	// The durations are the new baselines
+	d, ok := store.Load("flush")
+	require.True(t, ok)
+	require.Less(t, d, 300*time.Millisecond)
+
+	_, ok = store.Load("#2")
+	require.False(t, ok)
+}
+
+func Test_DurationStore_SlowPath(t *testing.T) {
+	var records []record
+
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		records = append(records, record{level: level, msg: msg, kv: kv})
+	})
+
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Store("db", time.Millisecond))
+
+	cl := New(WithLogger(logger), WithDurationStore(store))
+
+	cl.Add(func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}, WithName("db"))
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Len(t, records, 3)
+	require.Equal(t, LevelWarn, records[1].level)
+	require.Equal(t, "closer: function slower than its baseline", records[1].msg)
+	require.Equal(t, []any{"name", "db"}, records[1].kv[:2])
+}
+
+func Test_FileStore_HappyPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "durations.json")
+
+	store := NewFileStore(path)
+
+	_, ok := store.Load("db")
+	require.False(t, ok)
+
+	require.NoError(t, store.Store("db", time.Second))
+
+	// Nothing is persisted until the flush
+	_, err := os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, store.Flush())
+
+	// A new store reads the durations persisted by the previous one
+	d, ok := NewFileStore(path).Load("db")
+	require.True(t, ok)
+	require.Equal(t, time.Second, d)
+}
+
+func Test_FileStore_ErrorPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "durations.json")
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	store := NewFileStore(path)
+
+	_, ok := store.Load("db")
+	require.False(t, ok)
+	require.Error(t, NewFileStore(path).Store("db", time.Second))
+}
+
+// countingStore counts the flushes of a MemoryStore.
+type countingStore struct {
+	*MemoryStore
+	flushes int
+}
+
+func (s *countingStore) Flush() error {
+	s.flushes++
+	return nil
+}
+
+func Test_DurationStore_FlushPath(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore()}
+	cl := New(WithDurationStore(store))
+
+	for _, name := range []string{"db", "cache", "queue"} {
+		cl.Add(func(ctx context.Context) error { return nil }, WithName(name))
+	}
+
+	cl.AddStage(1, func(ctx context.Context) error { return nil }, WithName("server"))
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	// The durations of all the stages are flushed at once
+	require.Equal(t, 1, store.flushes)
+
+	for _, name := range []string{"db", "cache", "queue", "server"} {
+		_, ok := store.Load(name)
+		require.True(t, ok, name)
+	}
+
+	path := filepath.Join(t.TempDir(), "durations.json")
+	file := New(WithDurationStore(NewFileStore(path)))
+
+	file.Add(func(ctx context.Context) error { return nil }, WithName("db"))
+
+	require.NoError(t, file.Close(context.Background()))
+
+	_, ok := NewFileStore(path).Load("db")
+	require.True(t, ok)
+}
@@ -0,0 +1,258 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	rtdebug "runtime/debug"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// entry is a registered function together with its registration settings.
+type entry struct {
+	f     Func
+	index int // Registration index
+
+	stage    int
+	name     string
+	timeout  time.Duration
+	priority int
+	tags     []string
+	site     uintptr // Program counter of the registration call
+	retries  int
+	critical bool
+	cond     func() bool
+	delay    time.Duration
+	jitter   time.Duration
+	weight   int // Budget weight, see WithWeight
+	onDone   func(error, time.Duration)
+
+	disabled bool     // Whether the function is skipped, see Closer.Disable
+	taken    bool     // Whether the function is taken for closing
+	out      *outcome // Result of the run once taken
+}
+
+// FuncOption configures a single registration, see Closer.Add.
+type FuncOption func(*entry)
+
+// WithStage places the function in the given stage, see Closer.AddStage.
+func WithStage(stage int) FuncOption {
+	return func(e *entry) {
+		e.stage = stage
+	}
+}
+
+// WithName names the function. The name identifies the function in plans
+// and prefixes its errors; unnamed functions are identified by their
+// registration index, e.g. "#3".
+func WithName(name string) FuncOption {
+	return func(e *entry) {
+		e.name = name
+	}
+}
+
+// WithTimeout limits every attempt to close the function to d.
+func WithTimeout(d time.Duration) FuncOption {
+	return func(e *entry) {
+		e.timeout = d
+	}
+}
+
+// WithPriority sets the priority of the function within its stage:
+// functions with a higher priority are started first. The default is 0.
+func WithPriority(priority int) FuncOption {
+	return func(e *entry) {
+		e.priority = priority
+	}
+}
+
+// WithTags attaches tags to the function, so related functions can be
+// addressed together.
+func WithTags(tags ...string) FuncOption {
+	return func(e *entry) {
+		e.tags = append(e.tags, tags...)
+	}
+}
+
+// WithRetry retries the function up to n more times while it fails
+// and the context is not done.
+func WithRetry(n int) FuncOption {
+	return func(e *entry) {
+		e.retries = max(n, 0)
+	}
+}
+
+// WithCritical marks the function as critical, e.g. a WAL fsync: its
+// failure is reported with the "critical" prefix, triggers the hook set by
+// WithOnFatal and makes ExitCode non-zero, while the failures of the
+// best-effort functions, e.g. a metrics flush, are only logged and
+// reported.
+func WithCritical() FuncOption {
+	return func(e *entry) {
+		e.critical = true
+	}
+}
+
+// WithCondition makes the function run only if cond returns true at
+// shutdown time, e.g. to flush a cache only if it is dirty. Otherwise the
+// function is reported as skipped with ReasonCondition.
+func WithCondition(cond func() bool) FuncOption {
+	return func(e *entry) {
+		e.cond = cond
+	}
+}
+
+// WithDelay makes the function wait for d before it runs, e.g. until
+// the load balancer stops routing traffic to the instance.
+// The wait is cut short if the context is done.
+func WithDelay(d time.Duration) FuncOption {
+	return func(e *entry) {
+		e.delay = d
+	}
+}
+
+// WithJitter adds a random wait from 0 to d before the function runs,
+// so mass restarts don't hit a downstream dependency simultaneously.
+// It adds up with WithDelay and is cut short if the context is done.
+func WithJitter(d time.Duration) FuncOption {
+	return func(e *entry) {
+		e.jitter = d
+	}
+}
+
+// WithWeight sets the budget weight of the function in the function budget
+// mode (see WithFuncBudget): a function with weight 3, e.g. a large cache
+// flush, gets three times as much of the remaining time as a function with
+// weight 1. The default weight is 1, smaller values are treated as 1.
+func WithWeight(n int) FuncOption {
+	return func(e *entry) {
+		e.weight = max(n, 1)
+	}
+}
+
+// WithOnDone sets the hook called once the function returns, or is skipped
+// with an error (see WithSkipOnCancel), with its error and duration, so
+// the component that registered it can observe its own teardown outcome
+// without parsing the aggregated error.
+func WithOnDone(f func(err error, d time.Duration)) FuncOption {
+	return func(e *entry) {
+		e.onDone = f
+	}
+}
+
+// done passes the result of the function to its hook, if any.
+func (e entry) done(res Result) {
+	if e.onDone != nil {
+		e.onDone(res.Err, res.Duration)
+	}
+}
+
+// label returns the name of the entry used in plans and reports.
+func (e entry) label() string {
+	if e.name != "" {
+		return e.name
+	}
+
+	return fmt.Sprintf("#%d", e.index)
+}
+
+// hasTag reports whether the entry has the tag.
+func (e entry) hasTag(tag string) bool {
+	return slices.Contains(e.tags, tag)
+}
+
+// meta returns the metadata of the entry for the attempt n.
+func (e entry) meta(n int) FuncMeta {
+	return FuncMeta{Name: e.label(), Tags: e.tags, Stage: e.stage, Priority: e.priority, Attempt: n}
+}
+
+// metaContext passes the metadata of a function through its context.
+// The metadata is built only when it is read, so the functions that
+// don't read it don't pay for it.
+type metaContext struct {
+	context.Context
+	e        *entry
+	attempts atomic.Int32 // Number of the attempts so far
+}
+
+func (c *metaContext) Value(key any) any {
+	if _, ok := key.(metaKey); ok {
+		return c.e.meta(int(c.attempts.Load()))
+	}
+
+	return c.Context.Value(key)
+}
+
+// Decorator derives the context passed to a function from the context of
+// the shutdown and the metadata of the function, see WithContextDecorator.
+type Decorator func(ctx context.Context, meta FuncMeta) context.Context
+
+// run closes the function after its delay, retrying it if configured.
+// The metadata of the function is passed through meta, which counts the
+// attempts. The context of every attempt is derived by decorate, if any.
+func (e entry) run(ctx context.Context, clock Clock, meta *metaContext, decorate Decorator) error {
+	delay := e.delay
+
+	if e.jitter > 0 {
+		delay += rand.N(e.jitter)
+	}
+
+	sleep(ctx, clock, delay)
+
+	// The metadata is attached once for all the attempts
+	meta.Context = ctx
+	ctx = meta
+
+	err := e.attempt(ctx, clock, int(meta.attempts.Add(1)), decorate)
+
+	for retry := 0; err != nil && retry < e.retries && ctx.Err() == nil; retry++ {
+		err = e.attempt(ctx, clock, int(meta.attempts.Add(1)), decorate)
+	}
+
+	return e.annotate(err)
+}
+
+// annotate prefixes the error of the function with its name and criticality.
+func (e entry) annotate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if e.name != "" {
+		err = fmt.Errorf("%s: %w", e.name, err)
+	}
+
+	if e.critical {
+		err = fmt.Errorf("critical: %w", err)
+	}
+
+	return err
+}
+
+// attempt calls the function once, within its timeout if any,
+// n is the number of the attempt starting from 1. A panic of the
+// function is recovered and returned as its error.
+func (e entry) attempt(ctx context.Context, clock Clock, n int, decorate Decorator) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r, stack: rtdebug.Stack()}
+		}
+	}()
+
+	if decorate != nil {
+		meta := e.meta(n)
+		meta.Tags = slices.Clone(e.tags)
+		ctx = decorate(ctx, meta)
+	}
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = withTimeout(ctx, clock, e.timeout)
+		defer cancel()
+	}
+
+	return e.f(ctx)
+}
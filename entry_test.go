@@ -0,0 +1,191 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FuncOptions_NamePath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	cl.Add(func(ctx context.Context) error { return errors.New("timeout") }, WithName("db"))
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") })
+	cl.Add(func(ctx context.Context) error { return errors.New("broken") }, WithName("wal"), WithCritical())
+
+	require.Equal(t, []string{"db", "#1", "wal"}, cl.Plan())
+
+	err := cl.Close(context.Background())
+
+	require.EqualError(t, err, "closer.Close: db: timeout; failed; critical: wal: broken")
+}
+
+func Test_FuncOptions_StageAndPriorityPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close, WithName("low"), WithPriority(-1))
+	cl.Add(mcf.close, WithName("default"))
+	cl.Add(mcf.close, WithName("high"), WithPriority(10))
+	cl.Add(mcf.close, WithName("first"), WithStage(-1))
+	cl.AddStage(1, mcf.close, WithName("last"))
+
+	require.Equal(t, []string{"first", "high", "default", "low", "last"}, cl.Plan())
+}
+
+func Test_FuncOptions_TimeoutPath(t *testing.T) {
+	var cl Closer
+
+	var deadline time.Time
+
+	cl.Add(func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	start := time.Now()
+	err := cl.Close(context.Background())
+
+	require.ErrorContains(t, err, context.DeadlineExceeded.Error())
+	require.WithinDuration(t, start.Add(10*time.Millisecond), deadline, 5*time.Millisecond)
+}
+
+func Test_FuncOptions_RetryPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		retries  int
+		attempts int
+		failed   bool
+	}{
+		{name: "no_retry", failures: 1, retries: 0, attempts: 1, failed: true},
+		{name: "recovered", failures: 2, retries: 3, attempts: 3},
+		{name: "exhausted", failures: 5, retries: 2, attempts: 3, failed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				cl       Closer
+				attempts int
+			)
+
+			cl.Add(func(ctx context.Context) error {
+				attempts++
+
+				if attempts <= test.failures {
+					return errors.New("failed")
+				}
+
+				return nil
+			}, WithRetry(test.retries))
+
+			err := cl.Close(context.Background())
+
+			require.Equal(t, test.attempts, attempts)
+
+			if test.failed {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_FuncOptions_RetryCancelledPath(t *testing.T) {
+	var (
+		cl       Closer
+		attempts int
+	)
+
+	cl.Add(func(ctx context.Context) error {
+		attempts++
+		return errors.New("failed")
+	}, WithRetry(5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Error(t, cl.CloseOne(ctx))
+	require.Equal(t, 1, attempts)
+}
+
+func Test_FuncOptions_DelayPath(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []FuncOption
+		min  time.Duration
+		max  time.Duration
+	}{
+		{name: "delay", opts: []FuncOption{WithDelay(20 * time.Millisecond)}, min: 20 * time.Millisecond, max: time.Second},
+		{name: "jitter", opts: []FuncOption{WithJitter(20 * time.Millisecond)}, max: 20*time.Millisecond + time.Second},
+		{name: "both", opts: []FuncOption{WithDelay(20 * time.Millisecond), WithJitter(time.Millisecond)}, min: 20 * time.Millisecond, max: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				cl Closer
+				at time.Time
+			)
+
+			cl.Add(func(ctx context.Context) error {
+				at = time.Now()
+				return nil
+			}, test.opts...)
+
+			start := time.Now()
+
+			require.NoError(t, cl.Close(context.Background()))
+			require.GreaterOrEqual(t, at.Sub(start), test.min)
+			require.Less(t, at.Sub(start), test.max)
+		})
+	}
+}
+
+func Test_FuncOptions_DelayCancelledPath(t *testing.T) {
+	var cl Closer
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close, WithDelay(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The function still runs once the context is done
+	require.ErrorIs(t, cl.CloseOne(ctx), context.DeadlineExceeded)
+	require.Equal(t, 1, mcf.calledCount)
+}
+
+func Test_FuncOptions_OnDonePath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	errFailed := errors.New("failed")
+
+	var (
+		dbErr    error
+		dbTime   time.Duration
+		cacheErr = errors.New("not called")
+	)
+
+	cl.Add(func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return errFailed
+	}, WithName("db"), WithOnDone(func(err error, d time.Duration) {
+		dbErr, dbTime = err, d
+	}))
+	cl.Add(func(ctx context.Context) error { return nil }, WithOnDone(func(err error, d time.Duration) {
+		cacheErr = err
+	}))
+
+	require.Error(t, cl.Close(context.Background()))
+	require.ErrorIs(t, dbErr, errFailed)
+	require.GreaterOrEqual(t, dbTime, 10*time.Millisecond)
+	require.NoError(t, cacheErr)
+}
@@ -0,0 +1,88 @@
+package closer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// The environment variables read by FromEnv.
+const (
+	EnvTimeout  = "CLOSER_TIMEOUT"   // Duration like "30s", see WithCloseTimeout
+	EnvOrder    = "CLOSER_ORDER"     // "fifo" or "lifo", see WithReverseOrder
+	EnvLogLevel = "CLOSER_LOG_LEVEL" // "debug", "info", "warn" or "error", see WithLogLevel
+)
+
+// FromEnv configures the Closer from the environment variables set, for
+// the containerized deployments where flags and config files are awkward:
+// CLOSER_TIMEOUT, CLOSER_ORDER and CLOSER_LOG_LEVEL. It overrides the
+// options before it, so it usually comes last. An invalid value is
+// ignored with a warning to the logger, if configured before, or to
+// stderr.
+func FromEnv() Option {
+	return func(c *Closer) {
+		if v, ok := os.LookupEnv(EnvTimeout); ok {
+			d, err := time.ParseDuration(v)
+
+			if err == nil && d < 0 {
+				err = fmt.Errorf("negative duration")
+			}
+
+			if c.envError(EnvTimeout, v, err) {
+				c.closeTimeout = d
+			}
+		}
+
+		if v, ok := os.LookupEnv(EnvOrder); ok {
+			var err error
+
+			switch strings.ToLower(v) {
+			case "fifo":
+				c.lifo = false
+			case "lifo":
+				c.lifo = true
+			default:
+				err = fmt.Errorf("want fifo or lifo")
+			}
+
+			c.envError(EnvOrder, v, err)
+		}
+
+		if v, ok := os.LookupEnv(EnvLogLevel); ok {
+			level, err := parseLevel(v)
+
+			if c.envError(EnvLogLevel, v, err) {
+				c.logLevel = level
+			}
+		}
+	}
+}
+
+// envError warns about the invalid value of the variable, if err is not
+// nil, and reports whether the value is valid.
+func (c *Closer) envError(name, value string, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	if c.logger != nil {
+		c.log(LevelWarn, "closer: ignoring the environment variable", "name", name, "value", value, "error", err)
+	} else {
+		_, _ = io.WriteString(stderr, fmt.Sprintf("closer: ignoring %s=%q: %v\n", name, value, err))
+	}
+
+	return false
+}
+
+// parseLevel parses the name of a level, case-insensitively.
+func parseLevel(s string) (Level, error) {
+	for _, level := range []Level{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if strings.EqualFold(s, level.String()) {
+			return level, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown level")
+}
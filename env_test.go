@@ -0,0 +1,66 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FromEnv_HappyPath(t *testing.T) {
+	t.Setenv(EnvTimeout, "20ms")
+	t.Setenv(EnvOrder, "LIFO")
+	t.Setenv(EnvLogLevel, "error")
+
+	var levels []Level
+
+	cl := New(
+		WithSynchronousExecution(),
+		WithLogger(LoggerFunc(func(level Level, msg string, kv ...any) {
+			levels = append(levels, level)
+		})),
+		FromEnv(),
+	)
+
+	var order []int
+
+	for i := range 2 {
+		cl.Add(func(ctx context.Context) error {
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	require.ErrorIs(t, cl.Close(context.Background()), context.DeadlineExceeded)
+	require.Equal(t, []int{1, 0}, order)
+	require.NotEmpty(t, levels)
+
+	for _, level := range levels {
+		require.Equal(t, LevelError, level)
+	}
+}
+
+func Test_FromEnv_ErrorPath(t *testing.T) {
+	t.Setenv(EnvTimeout, "-1s")
+	t.Setenv(EnvOrder, "random")
+
+	var buf bytes.Buffer
+
+	prev := stderr
+	stderr = &buf
+	t.Cleanup(func() { stderr = prev })
+
+	cl := New(WithCloseTimeout(time.Hour), FromEnv())
+
+	require.Equal(t, time.Hour, cl.closeTimeout)
+	require.False(t, cl.lifo)
+	require.Contains(t, buf.String(), EnvTimeout)
+	require.Contains(t, buf.String(), EnvOrder)
+}
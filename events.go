@@ -0,0 +1,180 @@
+package closer
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	EventShutdownStarted  EventType = iota + 1 // Close started closing the functions
+	EventFuncStarted                           // A function started
+	EventFuncFinished                          // A function returned or was skipped
+	EventShutdownFinished                      // Close finished closing the functions
+	EventLateErrors                            // Functions abandoned by Close failed, see WithAbandon
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventShutdownStarted:
+		return "shutdown started"
+	case EventFuncStarted:
+		return "func started"
+	case EventFuncFinished:
+		return "func finished"
+	case EventShutdownFinished:
+		return "shutdown finished"
+	case EventLateErrors:
+		return "late errors"
+	default:
+		return "unknown"
+	}
+}
+
+// Event reports the progress of closing.
+type Event struct {
+	Type   EventType
+	Time   time.Time
+	Name   string // Name of the function, for the function events
+	Result Result // Result of the function, for EventFuncFinished
+	Err    error  // Error of Close, for EventShutdownFinished and EventLateErrors
+}
+
+// EventBufferSize is the capacity of the channels returned by Events.
+const EventBufferSize = 64
+
+// Sink receives the events of a Closer, e.g. to log them, count them in
+// metrics or turn them into trace spans. Several sinks can be subscribed
+// at once. Handle is called synchronously by the closing goroutines, so
+// it must be quick and safe for concurrent use.
+type Sink interface {
+	Handle(ev Event)
+}
+
+// SinkFunc is an adapter to use an ordinary function as a Sink.
+type SinkFunc func(ev Event)
+
+// Handle calls s(ev).
+func (s SinkFunc) Handle(ev Event) {
+	s(ev)
+}
+
+// LoggerSink returns the Sink logging every event with l, at the error
+// level for the failed functions and shutdowns, at the info level otherwise.
+func LoggerSink(l Logger) Sink {
+	return SinkFunc(func(ev Event) {
+		level := LevelInfo
+		kv := []any{"event", ev.Type.String()}
+
+		if ev.Name != "" {
+			kv = append(kv, "name", ev.Name)
+		}
+
+		if ev.Type == EventFuncFinished {
+			kv = append(kv, "status", ev.Result.Status.String(), "duration", ev.Result.Duration)
+		}
+
+		if err := eventErr(ev); err != nil {
+			level = LevelError
+			kv = append(kv, "error", err)
+		}
+
+		l.Log(level, "closer: event", kv...)
+	})
+}
+
+// eventErr returns the error the event carries, if any.
+func eventErr(ev Event) error {
+	if ev.Type == EventFuncFinished {
+		return ev.Result.Err
+	}
+
+	return ev.Err
+}
+
+// chanSink delivers the events to a channel without blocking,
+// dropping the events that don't fit into its buffer.
+type chanSink chan Event
+
+func (s chanSink) Handle(ev Event) {
+	select {
+	case s <- ev:
+	default:
+	}
+}
+
+// subscription is a sink subscribed to the events.
+type subscription struct {
+	id   int
+	sink Sink
+}
+
+// events is the bus delivering the events to the sinks.
+type events struct {
+	mu     sync.Mutex
+	subs   []subscription
+	nextID int
+	n      atomic.Int32 // Number of the sinks, read without the mutex
+}
+
+// Events returns a channel receiving the events of the Closer from now on,
+// until the returned function is called, like Subscribe. The channel is
+// buffered (see EventBufferSize) and never closed; closing never waits for
+// a subscriber, the events that don't fit into the buffer of a slow
+// subscriber are dropped.
+func (c *Closer) Events() (events <-chan Event, unsubscribe func()) {
+	ch := make(chan Event, EventBufferSize)
+
+	return ch, c.Subscribe(chanSink(ch))
+}
+
+// Subscribe makes the sink receive the events of the Closer from now on,
+// until the returned function is called. See also WithSink.
+func (c *Closer) Subscribe(s Sink) (unsubscribe func()) {
+	c.events.mu.Lock()
+	defer c.events.mu.Unlock()
+
+	id := c.events.nextID
+	c.events.nextID++
+
+	c.events.subs = append(c.events.subs, subscription{id: id, sink: s})
+	c.events.n.Store(int32(len(c.events.subs)))
+
+	return func() {
+		c.events.mu.Lock()
+		defer c.events.mu.Unlock()
+
+		c.events.subs = slices.DeleteFunc(c.events.subs, func(sub subscription) bool {
+			return sub.id == id
+		})
+		c.events.n.Store(int32(len(c.events.subs)))
+	}
+}
+
+// observed reports whether any sink is subscribed, so the events that
+// are costly to build are built only if delivered.
+func (c *Closer) observed() bool {
+	return c.events.n.Load() > 0
+}
+
+// emit delivers the event to the sinks. The sinks are called outside
+// of the lock, so they may subscribe or unsubscribe.
+func (c *Closer) emit(ev Event) {
+	c.events.mu.Lock()
+	subs := slices.Clone(c.events.subs)
+	c.events.mu.Unlock()
+
+	if len(subs) == 0 {
+		return
+	}
+
+	ev.Time = c.getClock().Now()
+
+	for _, sub := range subs {
+		sub.sink.Handle(ev)
+	}
+}
@@ -0,0 +1,150 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Events_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	errFailed := errors.New("failed")
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("db"))
+	cl.Add(func(ctx context.Context) error { return errFailed }, WithName("cache"))
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("queue"))
+	cl.Disable("queue")
+
+	events, unsubscribe := cl.Events()
+	defer unsubscribe()
+
+	err := cl.Close(context.Background())
+
+	expected := []struct {
+		typ    EventType
+		name   string
+		status Status
+	}{
+		{typ: EventShutdownStarted},
+		{typ: EventFuncFinished, name: "queue", status: StatusSkipped},
+		{typ: EventFuncStarted, name: "db"},
+		{typ: EventFuncFinished, name: "db", status: StatusClosed},
+		{typ: EventFuncStarted, name: "cache"},
+		{typ: EventFuncFinished, name: "cache", status: StatusFailed},
+		{typ: EventShutdownFinished},
+	}
+
+	for _, exp := range expected {
+		ev := <-events
+
+		require.Equal(t, exp.typ, ev.Type, ev.Type.String())
+		require.Equal(t, exp.name, ev.Name)
+		require.False(t, ev.Time.IsZero())
+
+		if exp.typ == EventFuncFinished {
+			require.Equal(t, exp.status, ev.Result.Status)
+		}
+
+		if exp.typ == EventShutdownFinished {
+			require.Equal(t, err, ev.Err)
+		}
+	}
+
+	require.Empty(t, events)
+}
+
+func Test_Events_SlowSubscriberPath(t *testing.T) {
+	var cl Closer
+
+	events, unsubscribe := cl.Events()
+	defer unsubscribe()
+
+	for range EventBufferSize {
+		cl.Add(func(ctx context.Context) error { return nil })
+	}
+
+	// Nobody reads the events, closing must not block
+	require.NoError(t, cl.Close(context.Background()))
+	require.Len(t, events, EventBufferSize)
+}
+
+func Test_Events_UnsubscribePath(t *testing.T) {
+	var cl Closer
+
+	events, unsubscribe := cl.Events()
+
+	cl.Add(func(ctx context.Context) error { return nil })
+	unsubscribe()
+
+	// The channel is not subscribed anymore
+	require.False(t, cl.observed())
+	require.NoError(t, cl.Close(context.Background()))
+	require.Empty(t, events)
+}
+
+func Test_Sink_HappyPath(t *testing.T) {
+	var first, second []EventType
+
+	cl := New(
+		WithSynchronousExecution(),
+		WithSink(SinkFunc(func(ev Event) { first = append(first, ev.Type) })),
+		WithSink(SinkFunc(func(ev Event) { second = append(second, ev.Type) })),
+	)
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	expected := []EventType{EventShutdownStarted, EventFuncStarted, EventFuncFinished, EventShutdownFinished}
+
+	require.Equal(t, expected, first)
+	require.Equal(t, expected, second)
+}
+
+func Test_Sink_UnsubscribePath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	var received []EventType
+
+	var unsubscribe func()
+
+	unsubscribe = cl.Subscribe(SinkFunc(func(ev Event) {
+		received = append(received, ev.Type)
+
+		// A sink may unsubscribe itself
+		if ev.Type == EventFuncStarted {
+			unsubscribe()
+		}
+	}))
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []EventType{EventShutdownStarted, EventFuncStarted}, received)
+}
+
+func Test_Sink_LoggerPath(t *testing.T) {
+	var records []record
+
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		records = append(records, record{level: level, msg: msg, kv: kv})
+	})
+
+	cl := New(WithSynchronousExecution(), WithSink(LoggerSink(logger)))
+
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") }, WithName("db"))
+
+	require.Error(t, cl.Close(context.Background()))
+	require.Len(t, records, 4)
+
+	require.Equal(t, LevelInfo, records[0].level)
+	require.Equal(t, []any{"event", "shutdown started"}, records[0].kv)
+
+	require.Equal(t, LevelError, records[2].level)
+	require.Equal(t, "closer: event", records[2].msg)
+	require.Equal(t, []any{"event", "func finished", "name", "db", "status", "failed"}, records[2].kv[:6])
+
+	require.Equal(t, LevelError, records[3].level)
+}
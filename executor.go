@@ -0,0 +1,33 @@
+package closer
+
+// Executor runs the workers closing the functions of a stage.
+// Applications can supply their own worker pools, panic handlers or
+// instrumented schedulers. Close waits for every f passed to Go to return.
+type Executor interface {
+	// Go runs f, usually in another goroutine.
+	Go(f func())
+}
+
+// ExecutorFunc is an adapter to use an ordinary function as an Executor.
+type ExecutorFunc func(f func())
+
+// Go calls e(f).
+func (e ExecutorFunc) Go(f func()) {
+	e(f)
+}
+
+// goExecutor is the default Executor starting a goroutine per call.
+type goExecutor struct{}
+
+func (goExecutor) Go(f func()) {
+	go f()
+}
+
+// getExecutor returns the configured executor or the default one.
+func (c *Closer) getExecutor() Executor {
+	if c.executor == nil {
+		return goExecutor{}
+	}
+
+	return c.executor
+}
@@ -0,0 +1,48 @@
+package closer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Executor_HappyPath(t *testing.T) {
+	var calls atomic.Int64
+
+	exec := ExecutorFunc(func(f func()) {
+		calls.Add(1)
+
+		go f()
+	})
+
+	cl := New(WithExecutor(exec), WithMaxConcurrency(2))
+	mocks := []*mockCloseFunc{{}, {}, {}, {}}
+
+	for _, mcf := range mocks {
+		cl.Add(mcf.close)
+	}
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	for _, mcf := range mocks {
+		require.Equal(t, 1, mcf.calledCount)
+	}
+
+	require.Equal(t, int64(2), calls.Load())
+}
+
+func Test_Executor_SingleFuncPath(t *testing.T) {
+	exec := ExecutorFunc(func(f func()) {
+		t.Fatal("executor must not be used for a single function")
+	})
+
+	cl := New(WithExecutor(exec))
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 1, mcf.calledCount)
+}
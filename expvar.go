@@ -0,0 +1,21 @@
+package closer
+
+// expvarState returns the state of the Closer published via expvar.
+func (c *Closer) expvarState() any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lastErr := ""
+
+	if c.err != nil {
+		lastErr = c.err.Error()
+	}
+
+	return map[string]any{
+		"registered":    c.size.Load(),
+		"remaining":     c.size.Load() - c.taken.Load(),
+		"closing":       c.closing > 0,
+		"last_error":    lastErr,
+		"last_duration": c.lastDuration.Seconds(),
+	}
+}
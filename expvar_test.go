@@ -0,0 +1,53 @@
+package closer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// expvarRuns makes the expvar names unique across the runs of a test,
+// e.g. with -count=2, as a published name can't be reused.
+var expvarRuns atomic.Int64
+
+func Test_Expvar_HappyPath(t *testing.T) {
+	name := fmt.Sprintf("%s_%d", t.Name(), expvarRuns.Add(1))
+	cl := New(WithExpvar(name))
+
+	state := func() map[string]any {
+		var m map[string]any
+
+		require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &m))
+
+		return m
+	}
+
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") })
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.Equal(t, map[string]any{
+		"registered":    2.0,
+		"remaining":     2.0,
+		"closing":       false,
+		"last_error":    "",
+		"last_duration": 0.0,
+	}, state())
+
+	require.Error(t, cl.Close(context.Background()))
+
+	s := state()
+
+	require.Equal(t, 2.0, s["registered"])
+	require.Equal(t, 0.0, s["remaining"])
+	require.Equal(t, "closer.Close: failed", s["last_error"])
+
+	require.Panics(t, func() {
+		New(WithExpvar(name))
+	})
+}
@@ -0,0 +1,51 @@
+package closer
+
+import "errors"
+
+// ExitCodeFatal is the exit code returned by ExitCode for a fatal error.
+const ExitCodeFatal = 1
+
+// ExitCode returns the exit code of the process for the error of Close
+// (or App.Run): 0 if err is nil or holds only failures of best-effort
+// functions, ExitCodeFatal if a critical function failed (see
+// WithCritical) or if err holds any other error, e.g. of a runner or of
+// the abandoned functions (see WithAbandon):
+//
+//	os.Exit(closer.ExitCode(app.Run(ctx)))
+func ExitCode(err error) int {
+	if fatal(err) {
+		return ExitCodeFatal
+	}
+
+	return 0
+}
+
+// fatal reports whether err is more than failures of best-effort functions.
+func fatal(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if fErr, ok := err.(FuncError); ok {
+		return fErr.Critical
+	}
+
+	// The functions still running at the deadline are judged by their own errors
+	if _, ok := err.(*DeadlineError); ok {
+		return false
+	}
+
+	var agg *AggregateError
+
+	if !errors.As(err, &agg) {
+		return true
+	}
+
+	for _, member := range agg.Errs {
+		if fatal(member) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OnFatal_HappyPath(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		fatals []string
+	)
+
+	cl := New(WithOnFatal(func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		fatals = append(fatals, name)
+	}))
+
+	cl.Add(func(ctx context.Context) error { return errors.New("fsync failed") }, WithName("wal"), WithCritical())
+	cl.Add(func(ctx context.Context) error { return errors.New("flush failed") }, WithName("metrics"))
+
+	err := cl.Close(context.Background())
+
+	require.Equal(t, []string{"wal"}, fatals)
+	require.Equal(t, ExitCodeFatal, ExitCode(err))
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, err, &agg)
+	require.Len(t, agg.Critical(), 1)
+	require.Equal(t, "wal", agg.Critical()[0].Name)
+}
+
+func Test_ExitCode_BestEffortPath(t *testing.T) {
+	cl := New()
+
+	cl.Add(func(ctx context.Context) error { return errors.New("flush failed") }, WithName("metrics"))
+
+	err := cl.Close(context.Background())
+
+	require.Error(t, err)
+	require.Zero(t, ExitCode(err))
+	require.Zero(t, ExitCode(nil))
+
+	// Any other error is fatal
+	require.Equal(t, ExitCodeFatal, ExitCode(cl.Close(context.Background())))
+}
+
+func Test_ExitCode_MaxErrorsPath(t *testing.T) {
+	cl := New(WithMaxErrors(1), WithSynchronousExecution())
+
+	cl.Add(func(ctx context.Context) error { return errors.New("flush failed") }, WithName("metrics"))
+	cl.Add(func(ctx context.Context) error { return errors.New("flush failed") }, WithName("traces"))
+	cl.Add(func(ctx context.Context) error { return errors.New("fsync failed") }, WithName("wal"), WithCritical())
+
+	err := cl.Close(context.Background())
+
+	// The critical error is kept over the cap
+	require.Equal(t, ExitCodeFatal, ExitCode(err))
+	require.EqualError(t, err, "closer.Close: metrics: flush failed; critical: wal: fsync failed; and 1 more errors")
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, err, &agg)
+	require.Len(t, agg.Critical(), 1)
+	require.Equal(t, 1, agg.Dropped)
+}
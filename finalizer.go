@@ -0,0 +1,67 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// setFinalizer arranges for the finalizer fallback of the Closer,
+// see WithFinalizer. The finalizer is set on the Closer rather than on
+// every resource: the functions reference their resources, so none can be
+// collected before the Closer, and the values given to AddAny may be of
+// any type, which runtime.SetFinalizer rejects. The warning and the
+// cleanup are per resource all the same, see finalize.
+func (c *Closer) setFinalizer() {
+	if c.finalizer > 0 {
+		runtime.SetFinalizer(c, (*Closer).finalize)
+	}
+}
+
+// finalize warns loudly about every function left by a Closer collected
+// without Close, naming it and its call site, and closes them on
+// a goroutine of their own, so the finalizers of the other objects are
+// not held up.
+func (c *Closer) finalize() {
+	left := c.left()
+
+	if len(left) == 0 {
+		return
+	}
+
+	msg := "closer: collected without Close, closing the function left"
+
+	for _, e := range left {
+		site := e.callSite()
+
+		if c.logger != nil {
+			c.logger.Log(LevelError, msg, "name", e.label(), "registered_at", site)
+		} else {
+			_, _ = io.WriteString(stderr, fmt.Sprintf("%s: %s registered at %s\n", msg, e.label(), site))
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.finalizer)
+		defer cancel()
+
+		_ = c.Close(ctx)
+	}()
+}
+
+// left returns the functions not taken for closing yet.
+func (c *Closer) left() []entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var left []entry
+
+	for _, e := range c.funcs[c.i:] {
+		if !e.taken {
+			left = append(left, e)
+		}
+	}
+
+	return left
+}
@@ -0,0 +1,105 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func Test_Finalizer_HappyPath(t *testing.T) {
+	var buf syncBuffer
+
+	prev := stderr
+	stderr = &buf
+	t.Cleanup(func() { stderr = prev })
+
+	closed := make(chan struct{})
+
+	// The Closer is dropped without Close
+	func() {
+		cl := New(WithFinalizer(time.Second))
+
+		cl.Add(func(ctx context.Context) error {
+			close(closed)
+			return nil
+		}, WithName("db"))
+	}()
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+
+		select {
+		case <-closed:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The warning names the resource and where it was registered
+	require.Contains(t, buf.String(), "closer: collected without Close, closing the function left: db registered at ")
+	require.Contains(t, buf.String(), "finalizer_test.go:")
+}
+
+func Test_Finalizer_ClosedPath(t *testing.T) {
+	var buf syncBuffer
+
+	prev := stderr
+	stderr = &buf
+	t.Cleanup(func() { stderr = prev })
+
+	finalized := make(chan struct{})
+
+	func() {
+		cl := New(WithFinalizer(time.Second))
+		cl.Add(func(ctx context.Context) error { return nil })
+
+		require.NoError(t, cl.Close(context.Background()))
+
+		// The marker tells the collection ran
+		marker := new(int)
+		runtime.SetFinalizer(marker, func(*int) { close(finalized) })
+	}()
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+
+		select {
+		case <-finalized:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Give the finalizer of the Closer a chance to run as well
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,68 @@
+package closer
+
+import (
+	"flag"
+	"time"
+)
+
+// The names of the flags registered by RegisterFlags.
+const (
+	FlagGracefulTimeout = "graceful-timeout"
+	FlagHardTimeout     = "hard-timeout"
+	FlagPreCloseDelay   = "pre-close-delay"
+)
+
+// hardExitCode is the exit code of the process killed after the hard
+// timeout set by the flag, unless WithKillAfter sets one.
+const hardExitCode = 1
+
+// RegisterFlags registers the shutdown knobs of the Closer as flags of fs,
+// flag.CommandLine if nil, with the current settings as the defaults, so
+// they are named the same across services:
+//
+//   - -graceful-timeout bounds the functions run by Close, see WithCloseTimeout;
+//   - -hard-timeout kills the process that long after the graceful
+//     deadline, with exit code 1 unless WithKillAfter sets one, see WithKillAfter;
+//   - -pre-close-delay is the wait before Close runs any function, see
+//     WithShutdownDelay.
+//
+// The flags must be parsed before the Closer is closed.
+func (c *Closer) RegisterFlags(fs *flag.FlagSet) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	fs.DurationVar(&c.closeTimeout, FlagGracefulTimeout, c.closeTimeout, "time limit of the graceful shutdown, none if 0")
+	fs.Var(&hardTimeout{c: c}, FlagHardTimeout, "time after the graceful timeout before the process is killed, never if 0")
+	fs.DurationVar(&c.shutdownDelay, FlagPreCloseDelay, c.shutdownDelay, "wait before the shutdown closes anything")
+}
+
+// hardTimeout is the flag.Value of the kill delay of a Closer.
+type hardTimeout struct {
+	c *Closer
+}
+
+func (h *hardTimeout) String() string {
+	// The flag package calls String on a zero value for the defaults
+	if h.c == nil {
+		return "0s"
+	}
+
+	return h.c.killAfter.String()
+}
+
+func (h *hardTimeout) Set(s string) error {
+	d, err := time.ParseDuration(s)
+
+	if err != nil {
+		return err
+	}
+
+	h.c.killAfter = d
+
+	if h.c.killCode == 0 {
+		h.c.killCode = hardExitCode
+	}
+
+	return nil
+}
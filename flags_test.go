@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterFlags_HappyPath(t *testing.T) {
+	cl := New(WithShutdownDelay(time.Second))
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+	cl.RegisterFlags(fs)
+
+	require.Equal(t, "1s", fs.Lookup(FlagPreCloseDelay).DefValue)
+
+	require.NoError(t, fs.Parse([]string{
+		"-graceful-timeout", "30s",
+		"-hard-timeout", "5s",
+		"-pre-close-delay", "2s",
+	}))
+
+	require.Equal(t, 30*time.Second, cl.closeTimeout)
+	require.Equal(t, 5*time.Second, cl.killAfter)
+	require.Equal(t, hardExitCode, cl.killCode)
+	require.Equal(t, 2*time.Second, cl.shutdownDelay)
+
+	// The kill code set by the option is kept
+	cl = New(WithKillAfter(0, 3))
+	fs = flag.NewFlagSet("app", flag.ContinueOnError)
+
+	cl.RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{"-hard-timeout", "5s"}))
+	require.Equal(t, 3, cl.killCode)
+
+	var buf bytes.Buffer
+
+	fs.SetOutput(&buf)
+	fs.PrintDefaults()
+	require.Contains(t, buf.String(), FlagHardTimeout)
+}
+
+func Test_RegisterFlags_ErrorPath(t *testing.T) {
+	var cl Closer
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	cl.RegisterFlags(fs)
+
+	require.Error(t, fs.Parse([]string{"-hard-timeout", "soon"}))
+	require.Zero(t, cl.killAfter)
+	require.Zero(t, cl.killCode)
+}
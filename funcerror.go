@@ -0,0 +1,82 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Category classifies the error of a function, so alerting can treat
+// timeouts differently from hard failures.
+type Category int
+
+const (
+	CategoryFailed   Category = iota // The function returned an error
+	CategoryTimedOut                 // The function exceeded a deadline
+	CategoryPanicked                 // The function panicked
+	CategorySkipped                  // The function was skipped, see WithSkipOnCancel
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryFailed:
+		return "failed"
+	case CategoryTimedOut:
+		return "timed out"
+	case CategoryPanicked:
+		return "panicked"
+	case CategorySkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// FuncError is the error of a single function, retrievable from the error
+// of Close with errors.As. Its message is the one of Err.
+type FuncError struct {
+	Name     string        // Name or registration index of the function
+	Index    int           // Registration index of the function
+	Stage    int           // Stage of the function
+	Category Category      // Kind of the failure
+	Duration time.Duration // Time the function took
+	Attempts int           // Number of attempts made, 0 if skipped, see WithRetry
+	Critical bool          // Whether the function is critical, see WithCritical
+	Stack    []byte        // Stack trace of the panic, for CategoryPanicked
+	Err      error         // Error of the function, prefixed with its name
+}
+
+func (e FuncError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the function.
+func (e FuncError) Unwrap() error {
+	return e.Err
+}
+
+// panicError is the error of a function that panicked.
+type panicError struct {
+	value any
+	stack []byte // Stack trace of the panic
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// categorize returns the category of the error of a function that ran
+// and, if it panicked, the stack trace of the panic.
+func categorize(err error) (Category, []byte) {
+	var pErr *panicError
+
+	switch {
+	case errors.As(err, &pErr):
+		return CategoryPanicked, pErr.stack
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryTimedOut, nil
+	default:
+		return CategoryFailed, nil
+	}
+}
@@ -0,0 +1,94 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FuncError_HappyPath(t *testing.T) {
+	cl := New()
+
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") }, WithName("db"))
+
+	err := cl.Close(context.Background())
+
+	var fErr FuncError
+
+	require.ErrorAs(t, err, &fErr)
+	require.Equal(t, "db", fErr.Name)
+	require.Equal(t, CategoryFailed, fErr.Category)
+	require.EqualError(t, fErr, "db: failed")
+	require.EqualError(t, err, "closer.Close: db: failed")
+}
+
+func Test_FuncError_TimedOutPath(t *testing.T) {
+	cl := New()
+
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	var fErr FuncError
+
+	require.ErrorAs(t, cl.Close(context.Background()), &fErr)
+	require.Equal(t, "#0", fErr.Name)
+	require.Equal(t, CategoryTimedOut, fErr.Category)
+	require.GreaterOrEqual(t, fErr.Duration, 10*time.Millisecond)
+	require.ErrorIs(t, fErr, context.DeadlineExceeded)
+}
+
+func Test_FuncError_PanickedPath(t *testing.T) {
+	cl := New()
+
+	cl.Add(func(ctx context.Context) error { panic("boom") }, WithName("cache"))
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	err := cl.Close(context.Background())
+
+	var fErr FuncError
+
+	require.ErrorAs(t, err, &fErr)
+	require.Equal(t, CategoryPanicked, fErr.Category)
+	require.EqualError(t, err, "closer.Close: cache: panic: boom")
+
+	// The stack trace points at the panic
+	require.Contains(t, string(fErr.Stack), "Test_FuncError_PanickedPath")
+	require.Contains(t, fmt.Sprintf("%+v", err), "\n\tcache (panicked, ")
+	require.Contains(t, fmt.Sprintf("%+v", err), "\n\t\tgoroutine ")
+}
+
+func Test_FuncError_SkippedPath(t *testing.T) {
+	cl := New(WithSkipOnCancel())
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var fErr FuncError
+
+	require.ErrorAs(t, cl.Close(ctx), &fErr)
+	require.Equal(t, CategorySkipped, fErr.Category)
+	require.Equal(t, "skipped", fErr.Category.String())
+}
+
+func Test_FuncError_DetailsPath(t *testing.T) {
+	cl := New()
+
+	cl.Add(func(ctx context.Context) error { return nil })
+	cl.AddStage(2, func(ctx context.Context) error { return errors.New("failed") }, WithName("db"), WithRetry(2))
+
+	var fErr FuncError
+
+	require.ErrorAs(t, cl.Close(context.Background()), &fErr)
+	require.Equal(t, "db", fErr.Name)
+	require.Equal(t, 1, fErr.Index)
+	require.Equal(t, 2, fErr.Stage)
+	require.Equal(t, 3, fErr.Attempts)
+}
@@ -0,0 +1,90 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Handle refers to a function added to a Closer. It's an interface, so the
+// fakes of Interface can return their own handles.
+type Handle interface {
+	// Close closes the function of the handle like CloseOne, exactly once:
+	// it returns nil at once if the function is already taken for closing,
+	// by this handle or by the Closer. A later Close of the Closer skips it.
+	// It allows to close a resource eagerly in the normal flow while
+	// keeping it registered for the crash-path shutdown.
+	Close(ctx context.Context) error
+	// Closed reports whether the function of the handle is taken for closing.
+	Closed() bool
+}
+
+// handle is the Handle of a function added to a Closer.
+type handle struct {
+	c     *Closer
+	index int // Registration index of the function
+}
+
+// Close closes the function of the handle, see Handle.
+func (h *handle) Close(ctx context.Context) error {
+	c := h.c
+
+	if c.reentrant(ctx) {
+		return fmt.Errorf("%s: %v", "closer.Handle.Close", ErrReentrantClose)
+	}
+
+	c.mu.Lock()
+
+	if e := &c.funcs[h.index]; !e.taken {
+		return c.closeEntry(ctx, e, nil)
+	}
+
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Closed reports whether the function of the handle is taken for closing.
+func (h *handle) Closed() bool {
+	h.c.mu.Lock()
+	defer h.c.mu.Unlock()
+
+	return h.c.funcs[h.index].taken
+}
+
+// AddOnCancel adds a function like Add and arranges for it to be closed as
+// soon as ctx is cancelled, with a context keeping the values of ctx,
+// whichever comes first: the cancellation or a Close of the Closer. The
+// function runs exactly once either way; the outcome of a run triggered by
+// ctx is reported in Results, the events and the logs.
+func (c *Closer) AddOnCancel(ctx context.Context, f Func, opts ...FuncOption) Handle {
+	var stop atomic.Pointer[func() bool]
+
+	if f != nil {
+		next := f
+
+		// Closed by the Closer, the function no longer waits for ctx
+		f = func(ctx context.Context) error {
+			if stop := stop.Load(); stop != nil {
+				(*stop)()
+			}
+
+			return next(ctx)
+		}
+	}
+
+	h := mustAdd(c.add("closer.AddOnCancel", f, opts))
+
+	stopFunc := context.AfterFunc(ctx, func() {
+		_ = h.Close(context.WithoutCancel(ctx))
+	})
+
+	stop.Store(&stopFunc)
+
+	// The function may have been taken before the stop function was stored
+	if h.Closed() {
+		stopFunc()
+	}
+
+	return h
+}
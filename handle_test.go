@@ -0,0 +1,118 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Handle_HappyPath(t *testing.T) {
+	cl := New()
+	mocks := []*mockCloseFunc{{}, {}}
+
+	h := cl.Add(mocks[0].close)
+	cl.Add(mocks[1].close)
+
+	require.False(t, h.Closed())
+	require.NoError(t, h.Close(context.Background()))
+	require.True(t, h.Closed())
+	require.Equal(t, 1, cl.Remaining())
+
+	// The function runs exactly once
+	require.NoError(t, h.Close(context.Background()))
+	require.NoError(t, cl.Close(context.Background()))
+
+	require.Equal(t, 1, mocks[0].calledCount)
+	require.Equal(t, 1, mocks[1].calledCount)
+}
+
+func Test_Handle_ClosedByCloserPath(t *testing.T) {
+	cl := New()
+	mock := &mockCloseFunc{}
+
+	h := cl.AddStage(1, mock.close)
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.True(t, h.Closed())
+	require.NoError(t, h.Close(context.Background()))
+	require.Equal(t, 1, mock.calledCount)
+}
+
+func Test_Handle_ErrorPath(t *testing.T) {
+	cl := New()
+
+	h := cl.Add(func(ctx context.Context) error {
+		return errors.New("busy")
+	}, WithName("db"))
+
+	require.EqualError(t, h.Close(context.Background()), "db: busy")
+	require.Equal(t, StatusFailed, cl.Results()[0].Status)
+}
+
+func Test_Handle_ReentrantPath(t *testing.T) {
+	cl := New()
+
+	var h Handle
+
+	h = cl.Add(func(ctx context.Context) error {
+		return h.Close(ctx)
+	})
+
+	require.EqualError(t, cl.Close(context.Background()), "closer.Close: closer.Handle.Close: "+ErrReentrantClose)
+}
+
+type onCancelKey struct{}
+
+func Test_AddOnCancel_HappyPath(t *testing.T) {
+	var cl Closer
+
+	mock := &mockCloseFunc{}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), onCancelKey{}, "request"))
+
+	var value any
+
+	h := cl.AddOnCancel(ctx, func(ctx context.Context) error {
+		value = ctx.Value(onCancelKey{})
+		return mock.close(ctx)
+	})
+
+	cancel()
+
+	require.Eventually(t, h.Closed, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return cl.Results()[0].Status == StatusClosed }, time.Second, time.Millisecond)
+
+	// The Closer doesn't run it again
+	require.Error(t, cl.Close(context.Background()))
+	require.Equal(t, 1, mock.calledCount)
+	require.Equal(t, "request", value)
+}
+
+func Test_AddOnCancel_ClosedFirstPath(t *testing.T) {
+	var cl Closer
+
+	mock := &mockCloseFunc{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.AddOnCancel(ctx, mock.close)
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	require.Equal(t, 1, mock.calledCount)
+}
+
+func Test_AddOnCancel_NilFuncPath(t *testing.T) {
+	var cl Closer
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.AddOnCancel: %v registered at %s", ErrNilFunc, lineAfter(t, 1)), func() {
+		cl.AddOnCancel(context.Background(), nil)
+	})
+}
@@ -0,0 +1,99 @@
+// Package health provides the /livez and /readyz HTTP handlers wired to
+// the state of a closer.Closer: the readiness fails as soon as the
+// shutdown begins, so the load balancer stops routing traffic, while the
+// liveness holds until the functions finish, so the orchestrator doesn't
+// kill the process in the middle of the teardown.
+package health
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ilKhr/closer"
+)
+
+// Check is an additional readiness check, e.g. a database ping.
+type Check func(ctx context.Context) error
+
+// Option configures a Probes created by New.
+type Option func(*Probes)
+
+// WithReadyCheck adds a check the readiness depends on as well.
+// The checks run with the context of the request.
+func WithReadyCheck(check Check) Option {
+	return func(p *Probes) {
+		p.checks = append(p.checks, check)
+	}
+}
+
+// WithLiveAfterClose keeps the liveness up after the functions finished,
+// for processes that keep running after Close.
+func WithLiveAfterClose() Option {
+	return func(p *Probes) {
+		p.liveAfterClose = true
+	}
+}
+
+// Probes serves the liveness and readiness probes of a Closer.
+type Probes struct {
+	cl             *closer.Closer
+	checks         []Check // Additional readiness checks
+	liveAfterClose bool    // Whether the liveness is up after the functions finished
+}
+
+// New creates the probes of cl configured with the given options.
+func New(cl *closer.Closer, opts ...Option) *Probes {
+	p := &Probes{cl: cl}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Register registers the handlers at /livez and /readyz of mux.
+func (p *Probes) Register(mux *http.ServeMux) {
+	mux.Handle("/livez", p.Live())
+	mux.Handle("/readyz", p.Ready())
+}
+
+// Live returns the liveness handler: it fails with 503 once a Close has
+// finished, unless WithLiveAfterClose is given.
+func (p *Probes) Live() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.liveAfterClose && p.cl.Finished() {
+			respond(w, http.StatusServiceUnavailable, "closed")
+			return
+		}
+
+		respond(w, http.StatusOK, "ok")
+	})
+}
+
+// Ready returns the readiness handler: it fails with 503 as soon as
+// the shutdown begins or one of the readiness checks fails.
+func (p *Probes) Ready() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.cl.ShuttingDown() {
+			respond(w, http.StatusServiceUnavailable, "shutting down")
+			return
+		}
+
+		for _, check := range p.checks {
+			if err := check(r.Context()); err != nil {
+				respond(w, http.StatusServiceUnavailable, err.Error())
+				return
+			}
+		}
+
+		respond(w, http.StatusOK, "ok")
+	})
+}
+
+// respond writes the status with the message as plain text.
+func respond(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(msg + "\n"))
+}
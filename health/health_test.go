@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ilKhr/closer"
+	"github.com/stretchr/testify/require"
+)
+
+// probe returns the status code of the path served by mux.
+func probe(mux *http.ServeMux, path string) int {
+	rec := httptest.NewRecorder()
+
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Code
+}
+
+func Test_Probes_HappyPath(t *testing.T) {
+	cl := closer.New(closer.WithShutdownDelay(50 * time.Millisecond))
+	mux := http.NewServeMux()
+
+	New(cl).Register(mux)
+
+	release := make(chan struct{})
+	running := make(chan struct{})
+
+	cl.Add(func(ctx context.Context) error {
+		close(running)
+		<-release
+		return nil
+	})
+
+	require.Equal(t, http.StatusOK, probe(mux, "/livez"))
+	require.Equal(t, http.StatusOK, probe(mux, "/readyz"))
+
+	done := make(chan error)
+
+	go func() { done <- cl.Close(context.Background()) }()
+
+	// The readiness fails during the grace period already
+	require.Eventually(t, func() bool {
+		return probe(mux, "/readyz") == http.StatusServiceUnavailable
+	}, time.Second, time.Millisecond)
+	require.Equal(t, http.StatusOK, probe(mux, "/livez"))
+
+	<-running
+	require.Equal(t, http.StatusOK, probe(mux, "/livez"))
+
+	close(release)
+	require.NoError(t, <-done)
+
+	require.Equal(t, http.StatusServiceUnavailable, probe(mux, "/livez"))
+	require.Equal(t, http.StatusServiceUnavailable, probe(mux, "/readyz"))
+}
+
+func Test_Probes_ReadyCheckPath(t *testing.T) {
+	cl := closer.New()
+	mux := http.NewServeMux()
+
+	var errDown error
+
+	New(cl, WithReadyCheck(func(ctx context.Context) error { return errDown })).Register(mux)
+
+	require.Equal(t, http.StatusOK, probe(mux, "/readyz"))
+
+	errDown = errors.New("database down")
+
+	require.Equal(t, http.StatusServiceUnavailable, probe(mux, "/readyz"))
+}
+
+func Test_Probes_LiveAfterClosePath(t *testing.T) {
+	cl := closer.New()
+	mux := http.NewServeMux()
+
+	New(cl, WithLiveAfterClose()).Register(mux)
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, http.StatusOK, probe(mux, "/livez"))
+}
@@ -0,0 +1,26 @@
+package closer
+
+import "context"
+
+// Interface is the API of a Closer used by the code registering and
+// closing functions, implemented by *Closer and closertest.Recorder, so
+// downstream packages can accept it and tests can substitute mocks, which
+// return their own implementation of Handle.
+type Interface interface {
+	// Add adds a function to the list for closing, see Closer.Add.
+	Add(f Func, opts ...FuncOption) Handle
+	// AddStage adds a function to the given stage, see Closer.AddStage.
+	AddStage(stage int, f Func, opts ...FuncOption) Handle
+	// Close closes all the functions, see Closer.Close.
+	Close(ctx context.Context) error
+	// CloseOne closes one function, see Closer.CloseOne.
+	CloseOne(ctx context.Context) error
+	// Size returns the number of added functions, see Closer.Size.
+	Size() int
+	// Remaining returns the number of functions not closed yet, see Closer.Remaining.
+	Remaining() int
+	// Err returns the error of the last finished Close, see Closer.Err.
+	Err() error
+}
+
+var _ Interface = (*Closer)(nil)
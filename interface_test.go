@@ -0,0 +1,44 @@
+package closer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCloser is a mock of Interface built without a Closer.
+type fakeCloser struct {
+	funcs []Func
+}
+
+func (f *fakeCloser) Add(fn Func, opts ...FuncOption) Handle {
+	f.funcs = append(f.funcs, fn)
+	return fakeHandle{}
+}
+
+func (f *fakeCloser) AddStage(stage int, fn Func, opts ...FuncOption) Handle {
+	return f.Add(fn, opts...)
+}
+
+func (f *fakeCloser) Close(ctx context.Context) error    { return nil }
+func (f *fakeCloser) CloseOne(ctx context.Context) error { return nil }
+func (f *fakeCloser) Size() int                          { return len(f.funcs) }
+func (f *fakeCloser) Remaining() int                     { return len(f.funcs) }
+func (f *fakeCloser) Err() error                         { return nil }
+
+// fakeHandle is the handle returned by fakeCloser.
+type fakeHandle struct{}
+
+func (fakeHandle) Close(ctx context.Context) error { return nil }
+func (fakeHandle) Closed() bool                    { return false }
+
+func Test_Interface_MockPath(t *testing.T) {
+	var cl Interface = &fakeCloser{}
+
+	h := cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, h.Close(context.Background()))
+	require.False(t, h.Closed())
+	require.Equal(t, 1, cl.Size())
+}
@@ -0,0 +1,44 @@
+package closer
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// exit terminates the process, replaced in tests.
+var exit = os.Exit
+
+// stderr receives the final report when no logger is configured.
+var stderr io.Writer = os.Stderr
+
+// watchKill terminates the process if Close has not returned the kill
+// delay after the deadline of ctx, if enabled. The returned function
+// stops the watch.
+func (c *Closer) watchKill(ctx context.Context, op string) (stop func()) {
+	deadline, ok := ctx.Deadline()
+
+	if c.killAfter <= 0 || !ok {
+		return func() {}
+	}
+
+	clock := c.getClock()
+	timer := clock.AfterFunc(deadline.Sub(clock.Now())+c.killAfter, func() {
+		c.kill(op)
+	})
+
+	return func() { timer.Stop() }
+}
+
+// kill reports the state of the Closer and exits with the kill code.
+func (c *Closer) kill(op string) {
+	report := c.String()
+
+	if c.logger != nil {
+		c.logger.Log(LevelError, "closer: killing the wedged process", "op", op, "code", c.killCode, "state", report)
+	} else {
+		_, _ = io.WriteString(stderr, "closer: killing the wedged process\n"+report)
+	}
+
+	exit(c.killCode)
+}
@@ -0,0 +1,165 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubExit replaces the process exit for the test, the codes are sent to
+// the returned channel.
+func stubExit(t *testing.T) <-chan int {
+	codes := make(chan int, 1)
+
+	prev := exit
+	exit = func(code int) { codes <- code }
+	t.Cleanup(func() { exit = prev })
+
+	return codes
+}
+
+// heldClock is the real time with timers firing on demand only,
+// unless they were stopped.
+type heldClock struct {
+	mu     sync.Mutex
+	timers []*heldTimer
+}
+
+func (c *heldClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c *heldClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &heldTimer{f: f}
+	c.timers = append(c.timers, timer)
+
+	return timer
+}
+
+// fire fires the timers not stopped and returns their number.
+func (c *heldClock) fire() int {
+	c.mu.Lock()
+	timers := c.timers
+	c.mu.Unlock()
+
+	fired := 0
+
+	for _, timer := range timers {
+		if timer.Stop() {
+			timer.f()
+			fired++
+		}
+	}
+
+	return fired
+}
+
+type heldTimer struct {
+	f       func()
+	stopped atomic.Bool
+}
+
+func (t *heldTimer) Stop() bool {
+	return t.stopped.CompareAndSwap(false, true)
+}
+
+func Test_KillAfter_HappyPath(t *testing.T) {
+	codes := stubExit(t)
+	clock := &heldClock{}
+
+	cl := New(WithKillAfter(time.Hour, 3), WithClock(clock))
+
+	cl.Add(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.Error(t, cl.Close(ctx))
+
+	// Close returned in time, so the kill timer is stopped and never fires
+	require.Zero(t, clock.fire())
+	require.Empty(t, codes)
+}
+
+func Test_KillAfter_WedgedPath(t *testing.T) {
+	codes := stubExit(t)
+
+	var buf bytes.Buffer
+
+	prev := stderr
+	stderr = &buf
+	t.Cleanup(func() { stderr = prev })
+
+	release := make(chan struct{})
+
+	cl := New(WithKillAfter(10*time.Millisecond, 3))
+
+	cl.Add(func(ctx context.Context) error {
+		<-release
+		return nil
+	}, WithName("wedged"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var code int
+
+	go func() {
+		code = <-codes
+		close(release)
+	}()
+
+	require.NoError(t, cl.Close(ctx))
+	require.Equal(t, 3, code)
+	require.Contains(t, buf.String(), "closer: killing the wedged process")
+	require.Contains(t, buf.String(), "wedged: closing")
+}
+
+func Test_KillAfter_LoggerPath(t *testing.T) {
+	codes := stubExit(t)
+
+	var (
+		records []record
+		mu      sync.Mutex
+	)
+
+	// The final report is logged by a goroutine of its own
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		mu.Lock()
+		records = append(records, record{level: level, msg: msg, kv: kv})
+		mu.Unlock()
+	})
+
+	release := make(chan struct{})
+
+	cl := New(WithKillAfter(10*time.Millisecond, 3), WithLogger(logger))
+
+	cl.Add(func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		<-codes
+		close(release)
+	}()
+
+	require.NoError(t, cl.Close(ctx))
+	require.Len(t, records, 3)
+	require.Equal(t, LevelError, records[1].level)
+	require.Equal(t, "closer: killing the wedged process", records[1].msg)
+}
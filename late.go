@@ -0,0 +1,73 @@
+package closer
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// closeRun is the state of a single Close shared with its stages.
+type closeRun struct {
+	op       string
+	reload   bool          // Whether the Close is a reload, see ReloadByTag
+	finished chan struct{} // Closed once the Close has set its error
+	kept     atomic.Int32  // Number of the errors kept, see WithMaxErrors
+	dropped  atomic.Int32  // Number of the errors dropped over the cap
+}
+
+// collectLate waits for the left functions abandoned by Close, see
+// WithAbandon, and adds their errors to the error of the Close once it
+// has returned, so the late failures aren't lost.
+func (c *Closer) collectLate(run *closeRun, results <-chan slot, left int) {
+	var (
+		late    []slot
+		dropped int // Late errors dropped over the cap
+	)
+
+	for range left {
+		s := <-results
+
+		if s.err == nil {
+			continue
+		}
+
+		if c.retain(&run.kept, s.err) {
+			late = append(late, s)
+		} else {
+			dropped++
+		}
+	}
+
+	if len(late) == 0 && dropped == 0 {
+		return
+	}
+
+	slices.SortFunc(late, func(a, b slot) int {
+		return a.index - b.index
+	})
+
+	errs := make([]error, len(late))
+
+	for i, s := range late {
+		errs[i] = s.err
+	}
+
+	<-run.finished
+
+	c.mu.Lock()
+
+	err := c.aggregate(run.op, errs, dropped)
+
+	// A reload doesn't set the error, the late failures are reported only
+	if !run.reload {
+		if prev, ok := c.err.(*AggregateError); ok {
+			err = c.aggregate(run.op, append(slices.Clip(prev.Errs), errs...), prev.Dropped+dropped)
+		}
+
+		c.err = err
+	}
+
+	c.mu.Unlock()
+
+	c.emit(Event{Type: EventLateErrors, Err: err})
+	c.log(LevelError, "closer: abandoned functions failed", "op", run.op, "error", err)
+}
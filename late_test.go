@@ -0,0 +1,69 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Abandon_HappyPath(t *testing.T) {
+	cl := New(WithAbandon())
+	events, unsubscribe := cl.Events()
+	defer unsubscribe()
+
+	release := make(chan struct{})
+
+	cl.Add(func(ctx context.Context) error {
+		// Ignores its context
+		<-release
+		return errors.New("late")
+	}, WithName("wedged"))
+	cl.Add(func(ctx context.Context) error { return errors.New("boom") }, WithName("db"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := cl.Close(ctx)
+
+	require.EqualError(t, err, "closer.Close: db: boom; 1 functions abandoned: context deadline exceeded; deadline exceeded while running wedged")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+
+	for ev := range events {
+		if ev.Type == EventLateErrors {
+			require.EqualError(t, ev.Err, "closer.Close: db: boom; 1 functions abandoned: context deadline exceeded; deadline exceeded while running wedged; wedged: late")
+			break
+		}
+	}
+
+	require.Equal(t, cl.Err().Error(), "closer.Close: db: boom; 1 functions abandoned: context deadline exceeded; deadline exceeded while running wedged; wedged: late")
+	require.Equal(t, StatusFailed, cl.Results()[0].Status)
+}
+
+func Test_Abandon_NoLateErrorPath(t *testing.T) {
+	cl := New(WithAbandon())
+	release := make(chan struct{})
+	returned := make(chan struct{})
+
+	cl.Add(func(ctx context.Context) error {
+		defer close(returned)
+		<-release
+
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, cl.Close(ctx), context.DeadlineExceeded)
+
+	close(release)
+	<-returned
+
+	// The late success leaves the error as is
+	require.EqualError(t, cl.Err(), "closer.Close: 1 functions abandoned: context deadline exceeded; deadline exceeded while running #0")
+}
@@ -0,0 +1,145 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+)
+
+// Level is the severity of a log record.
+type Level int
+
+const (
+	LevelDebug Level = iota - 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// Logger is the minimal logger the Closer writes its shutdown logs to.
+// kv holds alternating keys and values, like in slog.
+// Adapters are provided for slog, the standard log package, zap and logrus.
+type Logger interface {
+	Log(level Level, msg string, kv ...any)
+}
+
+// LoggerFunc is an adapter to use an ordinary function as a Logger.
+type LoggerFunc func(level Level, msg string, kv ...any)
+
+// Log calls l(level, msg, kv...).
+func (l LoggerFunc) Log(level Level, msg string, kv ...any) {
+	l(level, msg, kv...)
+}
+
+// SlogLogger adapts a *slog.Logger.
+func SlogLogger(l *slog.Logger) Logger {
+	return LoggerFunc(func(level Level, msg string, kv ...any) {
+		l.Log(context.Background(), slog.Level(level*4), msg, kv...)
+	})
+}
+
+// StdLogger adapts a *log.Logger, the records are printed as
+// "LEVEL msg key=value ...".
+func StdLogger(l *log.Logger) Logger {
+	return LoggerFunc(func(level Level, msg string, kv ...any) {
+		l.Print(level.String() + "\x20" + format(msg, kv))
+	})
+}
+
+// SugaredLogger is the subset of the methods of *zap.SugaredLogger
+// used by ZapLogger, so this package doesn't depend on zap.
+type SugaredLogger interface {
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+}
+
+// ZapLogger adapts a *zap.SugaredLogger (see zap.Logger.Sugar).
+func ZapLogger(l SugaredLogger) Logger {
+	return LoggerFunc(func(level Level, msg string, kv ...any) {
+		switch {
+		case level >= LevelError:
+			l.Errorw(msg, kv...)
+		case level >= LevelWarn:
+			l.Warnw(msg, kv...)
+		case level >= LevelInfo:
+			l.Infow(msg, kv...)
+		default:
+			l.Debugw(msg, kv...)
+		}
+	})
+}
+
+// FormatLogger is the subset of the methods of logrus.FieldLogger
+// (*logrus.Logger, *logrus.Entry) used by LogrusLogger,
+// so this package doesn't depend on logrus.
+type FormatLogger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// LogrusLogger adapts a logrus logger, the key-value pairs are appended
+// to the message as "key=value".
+func LogrusLogger(l FormatLogger) Logger {
+	return LoggerFunc(func(level Level, msg string, kv ...any) {
+		msg = format(msg, kv)
+
+		switch {
+		case level >= LevelError:
+			l.Errorf("%s", msg)
+		case level >= LevelWarn:
+			l.Warnf("%s", msg)
+		case level >= LevelInfo:
+			l.Infof("%s", msg)
+		default:
+			l.Debugf("%s", msg)
+		}
+	})
+}
+
+// format appends the key-value pairs to the message.
+func format(msg string, kv []any) string {
+	var b strings.Builder
+
+	b.WriteString(msg)
+
+	for i := 0; i < len(kv); i += 2 {
+		b.WriteString("\x20")
+
+		if i+1 < len(kv) {
+			fmt.Fprintf(&b, "%v=%v", kv[i], kv[i+1])
+		} else {
+			fmt.Fprintf(&b, "%v", kv[i])
+		}
+	}
+
+	return b.String()
+}
+
+// log writes the record if a logger is configured and the level is enabled,
+// see WithLogLevel.
+func (c *Closer) log(level Level, msg string, kv ...any) {
+	if c.logger != nil && level >= c.logLevel {
+		c.logger.Log(level, msg, kv...)
+	}
+}
@@ -0,0 +1,165 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	level Level
+	msg   string
+	kv    []any
+}
+
+func Test_Logger_HappyPath(t *testing.T) {
+	var records []record
+
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		records = append(records, record{level: level, msg: msg, kv: kv})
+	})
+
+	cl := New(WithLogger(logger), WithSynchronousExecution())
+
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") }, WithName("db"))
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.Error(t, cl.Close(context.Background()))
+	require.Len(t, records, 3)
+
+	require.Equal(t, LevelInfo, records[0].level)
+	require.Equal(t, "closer: shutdown started", records[0].msg)
+
+	require.Equal(t, LevelError, records[1].level)
+	require.Equal(t, "closer: function failed", records[1].msg)
+	require.Equal(t, "db", records[1].kv[1])
+
+	require.Equal(t, LevelError, records[2].level)
+	require.Equal(t, "closer: shutdown finished", records[2].msg)
+}
+
+func Test_Logger_VerbosePath(t *testing.T) {
+	var records []record
+
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		records = append(records, record{level: level, msg: msg, kv: kv})
+	})
+
+	cl := New(WithLogger(logger), WithVerbose(), WithSynchronousExecution())
+
+	cl.Add(func(ctx context.Context) error { return errors.New("failed") }, WithName("db"), WithRetry(2))
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("cache"))
+
+	require.Error(t, cl.Close(context.Background()))
+	require.Len(t, records, 4)
+
+	require.Equal(t, LevelError, records[1].level)
+	require.Equal(t, "closer: function finished", records[1].msg)
+	require.Equal(t, []any{"name", "db", "status", "failed", "attempts", 3}, withoutKey(records[1].kv, "duration", "error"))
+
+	require.Equal(t, LevelInfo, records[2].level)
+	require.Equal(t, "closer: function finished", records[2].msg)
+	require.Equal(t, []any{"name", "cache", "status", "closed"}, withoutKey(records[2].kv, "duration"))
+}
+
+// withoutKey returns kv without the pairs of the keys.
+func withoutKey(kv []any, keys ...string) []any {
+	var out []any
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		if !slices.Contains(keys, kv[i].(string)) {
+			out = append(out, kv[i], kv[i+1])
+		}
+	}
+
+	return out
+}
+
+func Test_StdLogger_HappyPath(t *testing.T) {
+	var buf bytes.Buffer
+
+	StdLogger(log.New(&buf, "", 0)).Log(LevelWarn, "message", "key", "value", "odd")
+
+	require.Equal(t, "WARN message key=value odd\n", buf.String())
+}
+
+func Test_SlogLogger_HappyPath(t *testing.T) {
+	var buf bytes.Buffer
+
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	})
+
+	logger := SlogLogger(slog.New(handler))
+
+	logger.Log(LevelDebug, "debug")
+	logger.Log(LevelError, "error", "key", "value")
+
+	require.Equal(t, "level=DEBUG msg=debug\nlevel=ERROR msg=error key=value\n", buf.String())
+}
+
+// fakeZap records the calls of the zap sugared logger methods.
+type fakeZap struct {
+	calls []string
+}
+
+func (f *fakeZap) Debugw(msg string, kv ...interface{}) { f.add("debug", msg, kv) }
+func (f *fakeZap) Infow(msg string, kv ...interface{})  { f.add("info", msg, kv) }
+func (f *fakeZap) Warnw(msg string, kv ...interface{})  { f.add("warn", msg, kv) }
+func (f *fakeZap) Errorw(msg string, kv ...interface{}) { f.add("error", msg, kv) }
+
+func (f *fakeZap) add(level, msg string, kv []interface{}) {
+	f.calls = append(f.calls, fmt.Sprintf("%s %s %v", level, msg, kv))
+}
+
+func Test_ZapLogger_HappyPath(t *testing.T) {
+	zap := &fakeZap{}
+	logger := ZapLogger(zap)
+
+	logger.Log(LevelDebug, "a")
+	logger.Log(LevelInfo, "b", "key", 1)
+	logger.Log(LevelWarn, "c")
+	logger.Log(LevelError, "d")
+
+	require.Equal(t, []string{"debug a []", "info b [key 1]", "warn c []", "error d []"}, zap.calls)
+}
+
+// fakeLogrus records the calls of the logrus formatting methods.
+type fakeLogrus struct {
+	calls []string
+}
+
+func (f *fakeLogrus) Debugf(format string, args ...interface{}) { f.add("debug", format, args) }
+func (f *fakeLogrus) Infof(format string, args ...interface{})  { f.add("info", format, args) }
+func (f *fakeLogrus) Warnf(format string, args ...interface{})  { f.add("warn", format, args) }
+func (f *fakeLogrus) Errorf(format string, args ...interface{}) { f.add("error", format, args) }
+
+func (f *fakeLogrus) add(level, format string, args []interface{}) {
+	f.calls = append(f.calls, level+"\x20"+fmt.Sprintf(format, args...))
+}
+
+func Test_LogrusLogger_HappyPath(t *testing.T) {
+	logrus := &fakeLogrus{}
+	logger := LogrusLogger(logrus)
+
+	logger.Log(LevelDebug, "a")
+	logger.Log(LevelInfo, "b", "key", "100%")
+	logger.Log(LevelWarn, "c")
+	logger.Log(LevelError, "d")
+
+	require.Equal(t, []string{"debug a", "info b key=100%", "warn c", "error d"}, logrus.calls)
+}
@@ -0,0 +1,422 @@
+package closer
+
+import (
+	"expvar"
+	"io"
+	"math/rand/v2"
+	"time"
+)
+
+// Option configures a Closer created by New.
+type Option func(*Closer)
+
+// WithStageBudget enables the deadline budget mode.
+// When the context passed to Close has a deadline, the remaining time is
+// divided across the remaining stages before each stage starts, so a slow
+// early stage can't starve the later ones. The time a stage does not use
+// is passed on to the following stages.
+// By default all stages have the same weight, see WithStageWeight.
+func WithStageBudget() Option {
+	return func(c *Closer) {
+		c.budget = true
+	}
+}
+
+// WithStageWeight sets the budget weight of a stage and enables the
+// deadline budget mode (see WithStageBudget). A stage with weight 2 gets
+// twice as much of the remaining time as a stage with weight 1.
+// Stages without an explicit weight have weight 1.
+func WithStageWeight(stage, weight int) Option {
+	return func(c *Closer) {
+		c.budget = true
+
+		if c.weights == nil {
+			c.weights = make(map[int]int)
+		}
+
+		c.weights[stage] = weight
+	}
+}
+
+// WithFuncBudget enables the function budget mode for the functions run
+// one by one: in the synchronous mode and by CloseOne. When the context has
+// a deadline, each function gets its share of the time left before it
+// starts, in proportion to its weight (see WithWeight), so a slow early
+// function can't leave nothing for the rest.
+// In the synchronous mode the time of a stage is divided among its
+// functions; combine it with WithStageBudget when there are several stages.
+// CloseOne divides the time among all the functions not closed yet.
+func WithFuncBudget() Option {
+	return func(c *Closer) {
+		c.funcBudget = true
+	}
+}
+
+// WithReverseOrder makes Close start the functions of a stage with the
+// same priority in the reverse order of registration, like defer does.
+// With WithSynchronousExecution the last function added is closed first.
+func WithReverseOrder() Option {
+	return func(c *Closer) {
+		c.lifo = true
+	}
+}
+
+// WithPriorityGroups makes Close run the functions of a stage grouped by
+// priority (see WithPriority): the groups run one after another in
+// descending order, the functions of a group concurrently. It is the
+// middle ground between concurrent and synchronous execution without
+// the need for a stage per step. In the budget mode every group counts
+// as a stage of the weight of its stage.
+func WithPriorityGroups() Option {
+	return func(c *Closer) {
+		c.priorityGroups = true
+	}
+}
+
+// WithReverseGroups makes Close close the groups (see Closer.Group) in the
+// reverse order of their creation, so the groups of the resources created
+// first, the ones the others depend on, are closed last.
+func WithReverseGroups() Option {
+	return func(c *Closer) {
+		c.reverseGroups = true
+	}
+}
+
+// WithSynchronousExecution makes Close run the functions of every stage
+// one by one on the calling goroutine, in the registration order.
+// Execution order and error aggregation become fully deterministic,
+// which is mostly useful in tests.
+func WithSynchronousExecution() Option {
+	return func(c *Closer) {
+		c.sync = true
+	}
+}
+
+// WithChaos enables the chaos mode: random delays, shuffled start order and
+// synthetic errors are injected into the functions according to cfg.
+// It is meant for tests validating that an application tolerates
+// imperfect shutdowns and must not be used in production.
+func WithChaos(cfg Chaos) Option {
+	return func(c *Closer) {
+		c.chaos = newChaos(cfg)
+	}
+}
+
+// WithShuffledOrder makes Close run the functions of every stage in
+// a random order, ignoring their priorities, to flush out hidden ordering
+// assumptions between components. The stages still run one after another,
+// so the dependencies expressed by them hold. The same seed gives the same
+// order; it is mostly useful together with WithSynchronousExecution
+// and is meant for test builds.
+func WithShuffledOrder(seed uint64) Option {
+	return func(c *Closer) {
+		c.shuffle = rand.NewPCG(seed, seed)
+	}
+}
+
+// WithClock sets the clock used by the timeout logic of the Closer,
+// such as the stage budget and the chaos delays.
+func WithClock(clock Clock) Option {
+	return func(c *Closer) {
+		c.clock = clock
+	}
+}
+
+// WithMaxConcurrency limits the number of functions of a stage that are
+// closed at once: Close runs them on a pool of n goroutines.
+// A value of 0 or less removes the limit, so a goroutine is started
+// per function. The default limit is DefaultMaxConcurrency.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Closer) {
+		if n <= 0 {
+			n = -1
+		}
+
+		c.maxConcurrency = n
+	}
+}
+
+// WithExecutor sets the executor running the workers that close the
+// functions of a stage. By default every worker gets its own goroutine.
+// The executor is not used in the synchronous mode and for stages of
+// a single function, which run on the calling goroutine.
+func WithExecutor(e Executor) Option {
+	return func(c *Closer) {
+		c.executor = e
+	}
+}
+
+// WithShutdownDelay makes Close wait for d before it runs any function,
+// covering the common "sleep a few seconds after SIGTERM" pattern, e.g. to
+// let the load balancer notice the instance is going away. The functions
+// added meanwhile are closed as well. The wait is cut short if the context
+// is done or Force is called.
+func WithShutdownDelay(d time.Duration) Option {
+	return func(c *Closer) {
+		c.shutdownDelay = d
+	}
+}
+
+// WithCloseTimeout bounds the functions run by Close and its variants
+// to d, whatever the deadline of the context passed, which still applies
+// if sooner. The shutdown delay (see WithShutdownDelay) is not counted.
+func WithCloseTimeout(d time.Duration) Option {
+	return func(c *Closer) {
+		c.closeTimeout = d
+	}
+}
+
+// WithAbandon makes Close stop waiting for the functions of a stage once
+// its context is done, so a function ignoring its context can't block the
+// caller: the functions still running are abandoned, Close goes on with
+// the next stages and returns. The errors of the abandoned functions are
+// still captured once they return: Results and Err include them, and
+// EventLateErrors reports the updated error of Close. Synchronous
+// execution (see WithSynchronousExecution) can't abandon a function.
+func WithAbandon() Option {
+	return func(c *Closer) {
+		c.abandon = true
+	}
+}
+
+// WithSkipOnCancel makes the functions that have not started yet be
+// skipped once the context is done, instead of being invoked pointlessly.
+// They are reported as skipped with ReasonContextDone and their errors
+// wrap the error of the context.
+func WithSkipOnCancel() Option {
+	return func(c *Closer) {
+		c.skipOnCancel = true
+	}
+}
+
+// WithFallbackTimeout enables the best-effort mode for an already done
+// context: if Close or CloseOne is called with a cancelled or expired
+// context, the functions still run, with a fresh context keeping the
+// values of the original one and bounded by d. It takes precedence over
+// WithSkipOnCancel for the contexts done before the call.
+func WithFallbackTimeout(d time.Duration) Option {
+	return func(c *Closer) {
+		c.fallback = d
+	}
+}
+
+// WithAggregator sets the way Close combines the errors of the functions,
+// see JoinErrors, FirstError and CapErrors.
+func WithAggregator(a Aggregator) Option {
+	return func(c *Closer) {
+		c.aggregator = a
+	}
+}
+
+// WithMaxErrors makes Close retain at most n errors of the functions, the
+// first ones to fail, and only count the rest ("and 1324 more errors"), so
+// a pathological shutdown of thousands of functions doesn't hold on to all
+// of their errors while the process is dying. Results keeps at most n errors
+// too: the functions failing over the cap report ErrDropped. The hooks and
+// the events still see every error as it occurs. The errors of the critical
+// functions are always kept, see WithCritical. The default is no limit.
+func WithMaxErrors(n int) Option {
+	return func(c *Closer) {
+		c.maxErrors = max(n, 0)
+	}
+}
+
+// WithLogger sets the logger of the shutdown: the start and the end of
+// Close and the failed functions are logged. See SlogLogger, StdLogger,
+// ZapLogger and LogrusLogger for the adapters.
+func WithLogger(l Logger) Option {
+	return func(c *Closer) {
+		c.logger = l
+	}
+}
+
+// WithLogLevel drops the records of the logger (see WithLogger) below
+// the level. The default is LevelInfo.
+func WithLogLevel(level Level) Option {
+	return func(c *Closer) {
+		c.logLevel = level
+	}
+}
+
+// WithSink subscribes the sink to the events of the Closer, see Subscribe.
+// It can be given several times to feed several observability backends.
+func WithSink(s Sink) Option {
+	return func(c *Closer) {
+		c.Subscribe(s)
+	}
+}
+
+// WithOnError sets the hook called as soon as each function fails, with
+// its name and error, so applications can alert on specific failures in
+// real time instead of waiting for the aggregated error. It is called by
+// the closing goroutines, so it must be safe for concurrent use.
+func WithOnError(f func(name string, err error)) Option {
+	return func(c *Closer) {
+		c.onError = f
+	}
+}
+
+// WithOnFatal sets the hook called as soon as a critical function (see
+// WithCritical) fails, with its name and error, e.g. to page the on-call
+// engineer, while the failures of the best-effort functions are only
+// logged and reported. It is called by the closing goroutines, so it must
+// be safe for concurrent use.
+func WithOnFatal(f func(name string, err error)) Option {
+	return func(c *Closer) {
+		c.onFatal = f
+	}
+}
+
+// WithDurationStore sets the store of the durations the named functions
+// took in the previous shutdowns, see NewMemoryStore and NewFileStore.
+// In the function budget mode (see WithFuncBudget) the time is divided in
+// proportion to these baselines, and a function taking more than twice
+// its baseline is logged as a warning (see WithLogger). The durations of
+// the functions closed successfully are stored as the new baselines, and
+// persisted once per Close if the store is a DurationFlusher.
+func WithDurationStore(s DurationStore) Option {
+	return func(c *Closer) {
+		c.store = s
+	}
+}
+
+// WithVerbose makes the logger of the shutdown (see WithLogger) get a
+// structured line per function with its name, duration, outcome and, if
+// it retries, attempt count, making every shutdown auditable.
+// By default only the failed functions are logged.
+func WithVerbose() Option {
+	return func(c *Closer) {
+		c.verbose = true
+	}
+}
+
+// WithContextDecorator sets the decorator deriving the context of every
+// attempt to close a function, before its timeout (see WithTimeout) is
+// applied. It allows per-function values, deadlines or trace propagation
+// without wrapping every function.
+func WithContextDecorator(d Decorator) Option {
+	return func(c *Closer) {
+		c.decorate = d
+	}
+}
+
+// LateAdd says what happens to the functions added while Close is running,
+// e.g. by a component reacting to the shutdown.
+type LateAdd int
+
+const (
+	// LateAddDeferred leaves them for the next Close or CloseOne.
+	LateAddDeferred LateAdd = iota
+	// LateAddFollowUp makes the running Close close them in a follow-up
+	// pass, stage by stage, once the current pass finishes; the passes are
+	// repeated until no function is added.
+	LateAddFollowUp
+)
+
+// WithLateAdd sets what happens to the functions added while Close is
+// running, LateAddDeferred by default. It applies to Close only:
+// CloseExcept, CloseUntil and ReloadByTag leave them for later anyway.
+func WithLateAdd(policy LateAdd) Option {
+	return func(c *Closer) {
+		c.lateAdd = policy
+	}
+}
+
+// WithStrict enables the strict mode: once a Close (CloseExcept,
+// CloseUntil) has begun, TryAdd returns an error naming the call site of
+// the registration, while Add, AddStage and Batch.Commit panic, so the
+// lifecycle wiring bugs are caught instead of silently dropping cleanups.
+// It takes precedence over WithLateAdd.
+func WithStrict() Option {
+	return func(c *Closer) {
+		c.strict = true
+	}
+}
+
+// WithKillAfter makes Close terminate the process with os.Exit(code) if
+// it still hasn't returned d after the deadline of its context, once the
+// final report (see Dump) is logged at the error level or, without
+// a logger, written to the standard error. It guarantees the process
+// actually exits even with a wedged function. Close without a deadline
+// is never killed.
+func WithKillAfter(d time.Duration, code int) Option {
+	return func(c *Closer) {
+		c.killAfter = d
+		c.killCode = code
+	}
+}
+
+// WithResourceReport sets the hook receiving a summary of the resources
+// of the process (goroutines, open file descriptors where available, heap
+// stats) once Close has run all the functions, to help detect resources
+// that survived the shutdown.
+func WithResourceReport(f func(ResourceReport)) Option {
+	return func(c *Closer) {
+		c.onReport = f
+	}
+}
+
+// WithSlowReport makes Close log the n slowest functions once it finished
+// (see WithLogger), with their durations and the fraction of the budget
+// each consumed: the time until the deadline of the context or, without
+// a deadline, the duration of Close. See also Slowest.
+func WithSlowReport(n int) Option {
+	return func(c *Closer) {
+		c.slowReport = n
+	}
+}
+
+// WithFinalizer enables the finalizer fallback: if the Closer is garbage
+// collected with functions left, i.e. the process somehow skipped Close,
+// an error naming every function left and its call site is logged (see
+// WithLogger, the standard error without one) and the functions are
+// closed best-effort within timeout. It is a last
+// resort: finalizers may run late or not at all, e.g. when the process
+// exits, and the Closer must not be reachable from its functions or from
+// other long-lived values, such as an expvar published by WithExpvar.
+func WithFinalizer(timeout time.Duration) Option {
+	return func(c *Closer) {
+		c.finalizer = timeout
+		c.setFinalizer()
+	}
+}
+
+// WithExpvar publishes the state of the Closer via expvar under the given
+// name: the number of registered and remaining functions, whether a Close
+// is in progress, the error and the duration (in seconds) of the last one.
+// Like expvar.Publish, it panics if the name is already in use.
+func WithExpvar(name string) Option {
+	return func(c *Closer) {
+		expvar.Publish(name, expvar.Func(c.expvarState))
+	}
+}
+
+// WithCloserName names the Closer, e.g. in the pprof labels.
+func WithCloserName(name string) Option {
+	return func(c *Closer) {
+		c.name = name
+	}
+}
+
+// WithPprofLabels runs every function with the pprof labels "closer.func"
+// (the name of the function) and "closer" (the name of the Closer, see
+// WithCloserName), so goroutine profiles taken during a hung shutdown
+// immediately show which resource is stuck. The goroutines started by
+// the function inherit the labels.
+func WithPprofLabels() Option {
+	return func(c *Closer) {
+		c.pprofLabels = true
+	}
+}
+
+// WithGoroutineDump makes Close capture a full goroutine dump as soon as
+// the deadline of its context is exceeded, while the stuck functions are
+// still running, so post-mortems of stuck shutdowns have the data they
+// need. The dump is written to w or, if w is nil, logged at the error
+// level under the "goroutines" key (see WithLogger).
+func WithGoroutineDump(w io.Writer) Option {
+	return func(c *Closer) {
+		c.dump = true
+		c.dumpWriter = w
+	}
+}
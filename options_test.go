@@ -0,0 +1,489 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SynchronousExecution_OrderPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	var order []int
+
+	for i := range 5 {
+		cl.Add(func(ctx context.Context) error {
+			order = append(order, i)
+
+			return fmt.Errorf("error %d", i)
+		})
+	}
+
+	err := cl.Close(context.Background())
+
+	require.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	require.EqualError(t, err, "closer.Close: error 0; error 1; error 2; error 3; error 4")
+}
+
+func Test_SynchronousExecution_HappyPath(t *testing.T) {
+	sizeTestCases := getTestCases()
+
+	for i, test := range sizeTestCases {
+		t.Run(fmt.Sprintf("Close_function_count_%d", i), func(t *testing.T) {
+			cl := New(WithSynchronousExecution())
+
+			for _, mcf := range test.mocks {
+				cl.Add(mcf.close)
+			}
+
+			err := cl.Close(context.Background())
+
+			for _, mcf := range test.mocks {
+				require.Equal(t, 1, mcf.calledCount)
+			}
+
+			if len(test.mocks) == 0 {
+				require.ErrorContains(t, err, ErrAllServicesClosed)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_MaxConcurrency_HappyPath(t *testing.T) {
+	const limit = 3
+
+	cl := New(WithMaxConcurrency(limit))
+
+	var (
+		running atomic.Int64
+		peak    atomic.Int64
+	)
+
+	for range 20 {
+		cl.Add(func(ctx context.Context) error {
+			storeMax(&peak, running.Add(1))
+			defer running.Add(-1)
+
+			time.Sleep(time.Millisecond)
+
+			return errors.New("error")
+		})
+	}
+
+	err := cl.Close(context.Background())
+
+	require.Equal(t, 20, strings.Count(err.Error(), "error"))
+	require.LessOrEqual(t, peak.Load(), int64(limit))
+}
+
+func Test_MaxConcurrency_DefaultPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		limit int64
+	}{
+		{name: "default", limit: DefaultMaxConcurrency},
+		{name: "unlimited", opts: []Option{WithMaxConcurrency(0)}, limit: 2 * DefaultMaxConcurrency},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := New(test.opts...)
+
+			var (
+				running atomic.Int64
+				peak    atomic.Int64
+				release = make(chan struct{})
+			)
+
+			for range 2 * DefaultMaxConcurrency {
+				cl.Add(func(ctx context.Context) error {
+					storeMax(&peak, running.Add(1))
+					defer running.Add(-1)
+
+					<-release
+
+					return nil
+				})
+			}
+
+			closed := make(chan error, 1)
+
+			go func() {
+				closed <- cl.Close(context.Background())
+			}()
+
+			// The functions are released even if the limit is never reached
+			func() {
+				defer close(release)
+
+				require.Eventually(t, func() bool {
+					return running.Load() == test.limit
+				}, 5*time.Second, time.Millisecond)
+			}()
+
+			require.NoError(t, <-closed)
+			require.LessOrEqual(t, peak.Load(), test.limit)
+		})
+	}
+}
+
+// storeMax stores n into v if n is greater.
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		p := v.Load()
+
+		if n <= p || v.CompareAndSwap(p, n) {
+			return
+		}
+	}
+}
+
+func Test_ShutdownDelay_HappyPath(t *testing.T) {
+	cl := New(WithShutdownDelay(20 * time.Millisecond))
+
+	var at time.Time
+
+	cl.Add(func(ctx context.Context) error {
+		at = time.Now()
+		return nil
+	})
+
+	start := time.Now()
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.GreaterOrEqual(t, at.Sub(start), 20*time.Millisecond)
+}
+
+func Test_ShutdownDelay_ForcePath(t *testing.T) {
+	cl := New(WithShutdownDelay(time.Hour))
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- cl.Close(context.Background())
+	}()
+
+	// Registered during the grace period
+	mcf2 := &mockCloseFunc{}
+	cl.Add(mcf2.close)
+
+	cl.Force()
+	cl.Force()
+
+	require.NoError(t, <-errCh)
+	require.Equal(t, 1, mcf.calledCount)
+	require.Equal(t, 1, mcf2.calledCount)
+}
+
+func Test_ShutdownDelay_CancelWithCtxPath(t *testing.T) {
+	cl := New(WithShutdownDelay(time.Hour))
+	mcf := &mockCloseFunc{}
+
+	cl.Add(mcf.close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorContains(t, cl.Close(ctx), context.Canceled.Error())
+	require.Equal(t, 1, mcf.calledCount)
+}
+
+func Test_ShuffledOrder_HappyPath(t *testing.T) {
+	run := func(seed uint64) []int {
+		cl := New(WithShuffledOrder(seed), WithSynchronousExecution())
+
+		var order []int
+
+		for i := range 10 {
+			cl.AddStage(i/5, func(ctx context.Context) error {
+				order = append(order, i)
+				return nil
+			})
+		}
+
+		require.NoError(t, cl.Close(context.Background()))
+
+		return order
+	}
+
+	order := run(42)
+
+	// The same seed gives the same order
+	require.Equal(t, order, run(42))
+
+	// The stages still run one after another
+	require.ElementsMatch(t, []int{0, 1, 2, 3, 4}, order[:5])
+	require.ElementsMatch(t, []int{5, 6, 7, 8, 9}, order[5:])
+
+	shuffled := false
+
+	for seed := range uint64(10) {
+		if !slices.IsSorted(run(seed)) {
+			shuffled = true
+		}
+	}
+
+	require.True(t, shuffled)
+}
+
+func Test_ContextDecorator_HappyPath(t *testing.T) {
+	type key struct{}
+
+	var (
+		metas  []FuncMeta
+		values []any
+	)
+
+	cl := New(WithSynchronousExecution(), WithContextDecorator(func(ctx context.Context, meta FuncMeta) context.Context {
+		metas = append(metas, meta)
+		return context.WithValue(ctx, key{}, "trace-"+meta.Name)
+	}))
+
+	cl.AddStage(1, func(ctx context.Context) error {
+		values = append(values, ctx.Value(key{}))
+
+		if AttemptFromContext(ctx) == 1 {
+			return errors.New("busy")
+		}
+
+		return nil
+	}, WithName("db"), WithTags("storage"), WithPriority(2), WithRetry(1))
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []FuncMeta{
+		{Name: "db", Tags: []string{"storage"}, Stage: 1, Priority: 2, Attempt: 1},
+		{Name: "db", Tags: []string{"storage"}, Stage: 1, Priority: 2, Attempt: 2},
+	}, metas)
+	require.Equal(t, []any{"trace-db", "trace-db"}, values)
+}
+
+func Test_ContextDecorator_CancelWithCtxPath(t *testing.T) {
+	cl := New(WithContextDecorator(func(ctx context.Context, meta FuncMeta) context.Context {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		return ctx
+	}))
+
+	cl.Add(func(ctx context.Context) error {
+		return ctx.Err()
+	})
+
+	require.ErrorContains(t, cl.Close(context.Background()), context.Canceled.Error())
+}
+
+func Test_LateAdd_HappyPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    LateAdd
+		calls     int
+		remaining int
+	}{
+		{name: "deferred", policy: LateAddDeferred, calls: 0, remaining: 1},
+		{name: "follow-up", policy: LateAddFollowUp, calls: 1, remaining: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := New(WithLateAdd(test.policy))
+			late := &mockCloseFunc{}
+
+			cl.Add(func(ctx context.Context) error {
+				// A component reacting to the shutdown
+				cl.Add(late.close)
+				return nil
+			})
+
+			require.NoError(t, cl.Close(context.Background()))
+			require.Equal(t, test.calls, late.calledCount)
+			require.Equal(t, test.remaining, cl.Remaining())
+		})
+	}
+}
+
+func Test_LateAdd_FollowUpPath(t *testing.T) {
+	cl := New(WithLateAdd(LateAddFollowUp), WithSynchronousExecution())
+
+	var order []string
+
+	cl.AddStage(1, func(ctx context.Context) error {
+		order = append(order, "db")
+
+		cl.Add(func(ctx context.Context) error {
+			order = append(order, "flush")
+
+			// The passes are repeated until no function is added
+			cl.Add(func(ctx context.Context) error {
+				order = append(order, "audit")
+				return errors.New("audit failed")
+			})
+
+			return nil
+		})
+
+		return nil
+	})
+
+	require.EqualError(t, cl.Close(context.Background()), "closer.Close: audit failed")
+	require.Equal(t, []string{"db", "flush", "audit"}, order)
+}
+
+func Test_Strict_HappyPath(t *testing.T) {
+	cl := New(WithStrict())
+	mock := &mockCloseFunc{}
+
+	h, err := cl.TryAdd(mock.close)
+
+	require.NoError(t, err)
+	require.NotNil(t, h)
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 1, mock.calledCount)
+}
+
+func Test_Strict_LatePath(t *testing.T) {
+	if debug {
+		t.Skip("TryAdd panics in debug builds")
+	}
+
+	cl := New(WithStrict())
+
+	cl.Add(func(ctx context.Context) error { return nil })
+	require.NoError(t, cl.Close(context.Background()))
+
+	site := lineAfter(t, 1)
+	h, err := cl.TryAdd(func(ctx context.Context) error { return nil })
+
+	require.Nil(t, h)
+	require.EqualError(t, err, fmt.Sprintf("closer.TryAdd: %v, registered at %s", ErrLateAdd, site))
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.Add: %v, registered at %s", ErrLateAdd, lineAfter(t, 1)), func() {
+		cl.Add(func(ctx context.Context) error { return nil })
+	})
+
+	b := cl.Batch()
+	b.Add(func(ctx context.Context) error { return nil })
+
+	require.Panics(t, func() { b.Commit() })
+	require.Equal(t, 1, cl.Size())
+
+	// Without the strict mode the function is left for the next Close
+	lax := New()
+
+	lax.Add(func(ctx context.Context) error { return nil })
+	require.NoError(t, lax.Close(context.Background()))
+
+	_, err = lax.TryAdd(func(ctx context.Context) error { return nil })
+	require.NoError(t, err)
+}
+
+func Test_Strict_OpenAllPath(t *testing.T) {
+	cl := New(WithStrict())
+
+	cl.Add(func(ctx context.Context) error { return nil })
+	require.NoError(t, cl.Close(context.Background()))
+
+	closed := false
+
+	cl.AddPair(func(ctx context.Context) error {
+		return nil
+	}, func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+
+	err := cl.OpenAll(context.Background())
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, err, &agg)
+	require.ErrorContains(t, err, "closer.OpenAll: "+ErrLateAdd)
+	require.True(t, closed)
+	require.Equal(t, 1, cl.Size())
+}
+
+func Test_OnError_HappyPath(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		failed = make(map[string]error)
+	)
+
+	errFailed := errors.New("failed")
+
+	cl := New(WithSkipOnCancel(), WithOnError(func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		failed[name] = err
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("db"))
+	cl.Add(func(ctx context.Context) error { return errFailed }, WithName("cache"))
+	cl.AddStage(1, func(ctx context.Context) error { return nil }, WithName("queue"))
+
+	// The skipped function of the next stage fails as well
+	cl.AddStage(0, func(ctx context.Context) error {
+		cancel()
+		return nil
+	})
+
+	require.Error(t, cl.Close(ctx))
+	require.Len(t, failed, 2)
+	require.ErrorIs(t, failed["cache"], errFailed)
+	require.ErrorIs(t, failed["queue"], context.Canceled)
+}
+
+func Test_PriorityGroups_HappyPath(t *testing.T) {
+	cl := New(WithPriorityGroups())
+
+	var (
+		mu    sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		order = append(order, name)
+	}
+
+	// The peers wait for each other, so they must run concurrently
+	wg.Add(2)
+
+	for _, name := range []string{"http", "grpc"} {
+		cl.Add(func(ctx context.Context) error {
+			wg.Done()
+			wg.Wait()
+			record(name)
+
+			return nil
+		}, WithPriority(1))
+	}
+
+	cl.Add(func(ctx context.Context) error {
+		record("db")
+		return nil
+	})
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Len(t, order, 3)
+	require.ElementsMatch(t, []string{"http", "grpc"}, order[:2])
+	require.Equal(t, "db", order[2])
+}
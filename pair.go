@@ -0,0 +1,104 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+)
+
+// pair is an Open/Close pair waiting for OpenAll.
+type pair struct {
+	open  Func
+	close Func
+	opts  []FuncOption
+}
+
+// AddPair adds a resource to open with open and to close with close,
+// the options configure the close function like for Add.
+// Nothing runs until OpenAll is called.
+// AddPair panics if open or close is nil.
+func (c *Closer) AddPair(open, close Func, opts ...FuncOption) {
+	if open == nil || close == nil {
+		panic(fmt.Sprintf("%s: %v registered at %s", "closer.AddPair", ErrNilFunc, callSite(1)))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pairs = append(c.pairs, pair{open: open, close: close, opts: opts})
+}
+
+// OpenAll opens the resources added by AddPair one by one, in the
+// registration order. If all of them open, their close functions are
+// added to the list for closing. If the k-th open fails, the resources
+// already opened are closed at once in reverse order and nothing is
+// added; the error combines the open error and the rollback errors.
+// With WithFallbackTimeout the rollback runs even if ctx is done.
+// In the strict mode, if the shutdown has begun, all the resources are
+// rolled back once opened.
+// Either way the pairs are consumed, so the next OpenAll opens only the
+// pairs added meanwhile.
+func (c *Closer) OpenAll(ctx context.Context) error {
+	op := "closer.OpenAll"
+
+	c.mu.Lock()
+	pairs := c.pairs
+	c.pairs = nil
+	c.mu.Unlock()
+
+	for k, p := range pairs {
+		if err := p.open(ctx); err != nil {
+			errs := []error{pairEntry(p).annotate(err)}
+			errs = append(errs, c.rollback(ctx, pairs[:k])...)
+
+			return c.aggregate(op, errs, 0)
+		}
+	}
+
+	c.mu.Lock()
+
+	// In the strict mode the shutdown may have begun meanwhile
+	if c.strict && c.shutdown && len(pairs) > 0 {
+		c.mu.Unlock()
+
+		// Prefixed with op by the AggregateError
+		late := fmt.Errorf("%v, registered at %s", ErrLateAdd, pairEntry(pairs[0]).callSite())
+		errs := append([]error{late}, c.rollback(ctx, pairs)...)
+
+		return c.aggregate(op, errs, 0)
+	}
+
+	for _, p := range pairs {
+		c.insert(pairEntry(p))
+	}
+
+	c.mu.Unlock()
+
+	return nil
+}
+
+// rollback closes the opened pairs in reverse order.
+func (c *Closer) rollback(ctx context.Context, opened []pair) []error {
+	ctx, cancel := c.fallbackContext(ctx)
+	defer cancel()
+
+	var errs []error
+
+	for i := len(opened) - 1; i >= 0; i-- {
+		if err := opened[i].close(ctx); err != nil {
+			errs = append(errs, pairEntry(opened[i]).annotate(err))
+		}
+	}
+
+	return errs
+}
+
+// pairEntry returns the entry the close function of p would be added as.
+func pairEntry(p pair) entry {
+	e := entry{f: p.close, weight: 1}
+
+	for _, opt := range p.opts {
+		opt(&e)
+	}
+
+	return e
+}
@@ -0,0 +1,103 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OpenAll_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	var calls []string
+
+	for _, name := range []string{"db", "cache"} {
+		cl.AddPair(func(ctx context.Context) error {
+			calls = append(calls, "open "+name)
+			return nil
+		}, func(ctx context.Context) error {
+			calls = append(calls, "close "+name)
+			return nil
+		}, WithName(name))
+	}
+
+	require.Equal(t, 0, cl.Size())
+	require.NoError(t, cl.OpenAll(context.Background()))
+	require.Equal(t, []string{"db", "cache"}, cl.Plan())
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []string{"open db", "open cache", "close db", "close cache"}, calls)
+
+	// The pairs are consumed
+	require.NoError(t, cl.OpenAll(context.Background()))
+	require.Len(t, calls, 4)
+}
+
+func Test_OpenAll_RollbackPath(t *testing.T) {
+	cl := New()
+
+	var calls []string
+
+	for i := range 4 {
+		cl.AddPair(func(ctx context.Context) error {
+			calls = append(calls, fmt.Sprintf("open %d", i))
+
+			if i == 2 {
+				return errors.New("refused")
+			}
+
+			return nil
+		}, func(ctx context.Context) error {
+			calls = append(calls, fmt.Sprintf("close %d", i))
+
+			if i == 0 {
+				return errors.New("busy")
+			}
+
+			return nil
+		}, WithName(fmt.Sprintf("r%d", i)))
+	}
+
+	err := cl.OpenAll(context.Background())
+
+	require.EqualError(t, err, "closer.OpenAll: r2: refused; r0: busy")
+	require.Equal(t, []string{"open 0", "open 1", "open 2", "close 1", "close 0"}, calls)
+	require.Equal(t, 0, cl.Size())
+}
+
+func Test_OpenAll_CancelWithCtxPath(t *testing.T) {
+	cl := New(WithFallbackTimeout(time.Second))
+
+	var closed bool
+
+	cl.AddPair(func(ctx context.Context) error {
+		return nil
+	}, func(ctx context.Context) error {
+		closed = ctx.Err() == nil
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.AddPair(func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	}, func(ctx context.Context) error {
+		return nil
+	})
+
+	require.ErrorContains(t, cl.OpenAll(ctx), context.Canceled.Error())
+	require.True(t, closed)
+}
+
+func Test_AddPair_NilPath(t *testing.T) {
+	cl := New()
+
+	require.PanicsWithValue(t, fmt.Sprintf("closer.AddPair: %v registered at %s", ErrNilFunc, lineAfter(t, 1)), func() {
+		cl.AddPair(nil, func(ctx context.Context) error { return nil })
+	})
+}
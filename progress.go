@@ -0,0 +1,72 @@
+package closer
+
+import (
+	"slices"
+	"sync"
+)
+
+// Progress reports the progress of the shutdown.
+type Progress struct {
+	Completed int      // Number of the functions closed, failed or skipped
+	Total     int      // Number of the registered functions
+	Running   []string // Names of the functions running, in the start order
+}
+
+// Progress returns a channel receiving the progress of the Closer from
+// now on, every time a function starts or finishes, so CLIs and TUIs can
+// render a progress indicator for long teardowns. The channel holds the
+// latest progress only: a slow reader skips the intermediate ones rather
+// than slowing closing down. It is never closed.
+func (c *Closer) Progress() <-chan Progress {
+	s := &progressSink{c: c, ch: make(chan Progress, 1)}
+
+	// The functions closed so far are counted once, the events count the rest
+	for _, res := range c.Results() {
+		if res.Status != StatusPending {
+			s.completed++
+		}
+	}
+
+	c.Subscribe(s)
+
+	return s.ch
+}
+
+// progressSink turns the events into the progress.
+type progressSink struct {
+	c  *Closer
+	ch chan Progress
+
+	mu        sync.Mutex
+	running   []string // Names of the functions running
+	completed int      // Number of the functions closed, failed or skipped
+}
+
+func (s *progressSink) Handle(ev Event) {
+	if ev.Type != EventFuncStarted && ev.Type != EventFuncFinished {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ev.Type == EventFuncStarted {
+		s.running = append(s.running, ev.Name)
+	} else {
+		s.completed++
+
+		if i := slices.Index(s.running, ev.Name); i >= 0 {
+			s.running = slices.Delete(s.running, i, i+1)
+		}
+	}
+
+	p := Progress{Completed: s.completed, Total: s.c.Size(), Running: slices.Clone(s.running)}
+
+	// Replace the progress not received yet with the latest one
+	select {
+	case <-s.ch:
+	default:
+	}
+
+	s.ch <- p
+}
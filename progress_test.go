@@ -0,0 +1,65 @@
+package closer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Progress_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	progress := cl.Progress()
+
+	var seen []Progress
+
+	cl.Add(func(ctx context.Context) error {
+		seen = append(seen, <-progress)
+		return nil
+	}, WithName("db"))
+	cl.Add(func(ctx context.Context) error {
+		seen = append(seen, <-progress)
+		return nil
+	}, WithName("cache"))
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	seen = append(seen, <-progress)
+
+	require.Equal(t, []Progress{
+		{Completed: 0, Total: 2, Running: []string{"db"}},
+		{Completed: 1, Total: 2, Running: []string{"cache"}},
+		{Completed: 2, Total: 2, Running: []string{}},
+	}, seen)
+}
+
+func Test_Progress_SlowReaderPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	progress := cl.Progress()
+
+	for range 3 {
+		cl.Add(func(ctx context.Context) error { return nil })
+	}
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	// Only the latest progress is kept
+	require.Equal(t, Progress{Completed: 3, Total: 3, Running: []string{}}, <-progress)
+	require.Empty(t, progress)
+}
+
+func Test_Progress_AlreadyClosedPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	for range 3 {
+		cl.Add(func(ctx context.Context) error { return nil })
+	}
+
+	require.NoError(t, cl.CloseOne(context.Background()))
+
+	// The function closed before the subscription is counted
+	progress := cl.Progress()
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, Progress{Completed: 3, Total: 3, Running: []string{}}, <-progress)
+}
@@ -0,0 +1,93 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ReloadByTag closes the not yet closed functions with the tag (see
+// WithTags) like Close, then calls reopen, which is expected to reopen
+// the resources and add their new close functions. The rest of the
+// functions stay registered, so config-reload flows can recycle a subset
+// of the resources while the process keeps running. A reload has no grace
+// period (see WithShutdownDelay) and doesn't count as a finished Close for
+// Err and WaitClosed. reopen is called even if nothing had the tag or
+// closing failed; the error, an AggregateError, combines both failures.
+func (c *Closer) ReloadByTag(ctx context.Context, tag string, reopen func(ctx context.Context) error) error {
+	op := "closer.ReloadByTag"
+
+	if c.reentrant(ctx) {
+		return fmt.Errorf("%s: %v", op, ErrReentrantClose)
+	}
+
+	var (
+		errs    []error
+		dropped int
+	)
+
+	keep := func(e entry) bool {
+		return !e.hasTag(tag)
+	}
+
+	if c.pending(keep) {
+		var agg *AggregateError
+
+		// The errors of the functions are combined with the reopen one
+		if err := c.close(ctx, op, keep, true); errors.As(err, &agg) {
+			errs, dropped = agg.Errs, agg.Dropped
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := reopen(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("reopen: %w", err))
+	}
+
+	if len(errs) == 0 && dropped == 0 {
+		return nil
+	}
+
+	return c.aggregate(op, errs, dropped)
+}
+
+// ReloadOnSignal runs ReloadByTag with the tag and reopen every time one
+// of the signals arrives, SIGHUP if none is given, until ctx is done or
+// the returned stop function is called. The errors of the reloads are
+// logged (see WithLogger). See RouteSignals to handle other signals too.
+// Where there is no SIGHUP, e.g. on Windows, it does nothing if no signal
+// is given.
+func (c *Closer) ReloadOnSignal(ctx context.Context, tag string, reopen func(ctx context.Context) error, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = reloadSignals
+	}
+
+	// No signal to wait for on this platform
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	routes := make(map[os.Signal]SignalAction, len(sigs))
+
+	for _, sig := range sigs {
+		routes[sig] = c.ReloadAction(tag, reopen)
+	}
+
+	return c.RouteSignals(ctx, routes)
+}
+
+// pending reports whether some function not taken yet is not to keep.
+func (c *Closer) pending(keep func(entry) bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := c.i; i < len(c.funcs); i++ {
+		if !c.funcs[i].taken && !keep(c.funcs[i]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package closer
+
+import (
+	"os"
+)
+
+// reloadSignals are the signals ReloadOnSignal waits for by default:
+// none, as there is no SIGHUP on this platform.
+var reloadSignals []os.Signal
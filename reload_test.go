@@ -0,0 +1,92 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReloadByTag_HappyPath(t *testing.T) {
+	cl := New()
+
+	var closed []string
+
+	add := func(name string, tags ...string) {
+		cl.Add(func(ctx context.Context) error {
+			closed = append(closed, name)
+			return nil
+		}, WithName(name), WithTags(tags...))
+	}
+
+	add("log")
+	add("db", "config")
+
+	err := cl.ReloadByTag(context.Background(), "config", func(ctx context.Context) error {
+		add("db2", "config")
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"db"}, closed)
+	require.Equal(t, []string{"log", "db2"}, cl.Plan())
+
+	// A reload is not a shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, cl.WaitClosed(ctx), context.DeadlineExceeded)
+}
+
+func Test_ReloadByTag_ErrorPath(t *testing.T) {
+	cl := New()
+
+	cl.Add(func(ctx context.Context) error {
+		return errors.New("busy")
+	}, WithTags("config"))
+
+	err := cl.ReloadByTag(context.Background(), "config", func(ctx context.Context) error {
+		return errors.New("bad config")
+	})
+
+	require.EqualError(t, err, "closer.ReloadByTag: busy; reopen: bad config")
+
+	var agg *AggregateError
+
+	require.ErrorAs(t, err, &agg)
+	require.Len(t, agg.Errs, 2)
+	require.Len(t, agg.Failed(), 1)
+
+	// Nothing to close, reopen is called anyway
+	var reopened bool
+
+	err = cl.ReloadByTag(context.Background(), "config", func(ctx context.Context) error {
+		reopened = true
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.True(t, reopened)
+}
+
+func Test_ReloadOnSignal_HappyPath(t *testing.T) {
+	cl := New()
+	reloaded := make(chan struct{})
+
+	stop := cl.ReloadOnSignal(context.Background(), "config", func(ctx context.Context) error {
+		close(reloaded)
+		return nil
+	})
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("no reload on SIGHUP")
+	}
+}
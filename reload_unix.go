@@ -0,0 +1,11 @@
+//go:build unix
+
+package closer
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals are the signals ReloadOnSignal waits for by default.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,51 @@
+package closer
+
+import (
+	"os"
+	"runtime"
+)
+
+// ResourceReport is the summary of the resources of the process taken
+// once a Close has run all the functions, see WithResourceReport.
+type ResourceReport struct {
+	Goroutines  int    // Number of goroutines
+	OpenFDs     int    // Number of open file descriptors, -1 where unavailable
+	HeapAlloc   uint64 // Bytes of allocated heap objects
+	HeapObjects uint64 // Number of allocated heap objects
+	NumGC       uint32 // Number of completed GC cycles
+}
+
+// resourceReport takes the summary of the resources of the process.
+func resourceReport() ResourceReport {
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+
+	return ResourceReport{
+		Goroutines:  runtime.NumGoroutine(),
+		OpenFDs:     openFDs(),
+		HeapAlloc:   stats.HeapAlloc,
+		HeapObjects: stats.HeapObjects,
+		NumGC:       stats.NumGC,
+	}
+}
+
+// openFDs returns the number of open file descriptors of the process,
+// -1 if the platform doesn't list them.
+func openFDs() int {
+	fds, err := os.ReadDir("/proc/self/fd")
+
+	if err != nil {
+		return -1
+	}
+
+	// The directory being read is open itself
+	return len(fds) - 1
+}
+
+// report passes the resource report to the hook, if any.
+func (c *Closer) report() {
+	if c.onReport != nil {
+		c.onReport(resourceReport())
+	}
+}
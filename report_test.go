@@ -0,0 +1,42 @@
+package closer
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResourceReport_HappyPath(t *testing.T) {
+	var reports []ResourceReport
+
+	cl := New(WithResourceReport(func(r ResourceReport) {
+		reports = append(reports, r)
+	}))
+
+	cl.Add(func(ctx context.Context) error { return nil })
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Len(t, reports, 1)
+	require.Positive(t, reports[0].Goroutines)
+	require.Positive(t, reports[0].HeapAlloc)
+
+	if runtime.GOOS == "linux" {
+		require.Positive(t, reports[0].OpenFDs)
+	}
+}
+
+func Test_ResourceReport_ReloadPath(t *testing.T) {
+	var reports []ResourceReport
+
+	cl := New(WithResourceReport(func(r ResourceReport) {
+		reports = append(reports, r)
+	}))
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithTags("config"))
+
+	// A reload is not a shutdown
+	require.NoError(t, cl.ReloadByTag(context.Background(), "config", func(ctx context.Context) error { return nil }))
+	require.Empty(t, reports)
+}
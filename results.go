@@ -0,0 +1,315 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the outcome of closing a function.
+type Status int
+
+const (
+	StatusPending Status = iota // The function is not closed yet
+	StatusClosed                // The function returned nil
+	StatusFailed                // The function returned an error
+	StatusSkipped               // The function was not run, see Result.Reason
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusClosed:
+		return "closed"
+	case StatusFailed:
+		return "failed"
+	case StatusSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	ReasonDisabled    = "disabled"
+	ReasonCondition   = "condition not met"
+	ReasonContextDone = "skipped due to cancelled context"
+)
+
+// Result describes how a registered function was closed.
+type Result struct {
+	Name     string        // Name or registration index of the function
+	Status   Status        // Outcome of closing the function
+	Err      error         // Error returned by the function, ErrDropped over the cap (see WithMaxErrors)
+	Reason   string        // Why the function was skipped
+	Duration time.Duration // Time the function took
+}
+
+// Results returns the results of all the registered functions
+// in the registration order.
+func (c *Closer) Results() []Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.collectResults()
+}
+
+// collectResults returns the results of all the registered functions.
+// It must be called under the mutex.
+func (c *Closer) collectResults() []Result {
+	results := make([]Result, len(c.funcs))
+
+	for i, e := range c.funcs {
+		results[i] = e.result()
+	}
+
+	return results
+}
+
+const (
+	outcomePending int32 = iota // The function is not running yet
+	outcomeRunning              // The function is running
+	outcomeDone                 // The result of the function is set
+)
+
+// outcome is where the result of a taken function goes, the name is left
+// to its entry. The outcomes of a Close are allocated at once, see
+// snapshot. An outcome is written without the mutex by the only goroutine
+// that took the function, the state publishes the result to the readers.
+type outcome struct {
+	state    atomic.Int32 // outcomePending, outcomeRunning or outcomeDone
+	status   Status
+	err      error
+	reason   string
+	duration time.Duration
+}
+
+// set sets the result of the function.
+func (o *outcome) set(res Result) {
+	o.status, o.err, o.reason, o.duration = res.Status, res.Err, res.Reason, res.Duration
+	o.state.Store(outcomeDone)
+}
+
+// setResult sets the result of the entry. Over the cap of the errors (see
+// WithMaxErrors), the result keeps ErrDropped rather than the error, the
+// error itself is not retained.
+func (c *Closer) setResult(e *entry, res Result) {
+	if res.Err != nil && !c.retain(&c.retained, res.Err) {
+		res.Err = errDropped
+	}
+
+	e.out.set(res)
+}
+
+// result returns the result of the entry, pending if it's not set yet.
+func (e *entry) result() Result {
+	if e.out == nil || e.out.state.Load() != outcomeDone {
+		return Result{Name: e.label()}
+	}
+
+	return Result{Name: e.label(), Status: e.out.status, Err: e.out.err, Reason: e.out.reason, Duration: e.out.duration}
+}
+
+// skip reports the entry as skipped for the reason and returns the result,
+// the caller emits the event.
+func (e *entry) skip(reason string) Result {
+	res := Result{Name: e.label(), Status: StatusSkipped, Reason: reason}
+
+	e.out.set(res)
+
+	return res
+}
+
+// task is a function taken for closing, ready to run: it reports its
+// result when it returns. The tasks of a Close are allocated at once,
+// see snapshot.
+type task struct {
+	c     *Closer
+	e     *entry // Entry of the function, owned by the task
+	clock Clock
+	f     Func        // Function with the chaos applied, if enabled
+	meta  metaContext // Context of the function, counting its attempts
+	name  string      // Name of the function once built, see label
+}
+
+// label returns the name of the function, built once.
+func (t *task) label() string {
+	if t.name == "" {
+		t.name = t.e.label()
+	}
+
+	return t.name
+}
+
+// call runs the function of the entry with its settings applied.
+func (t *task) call(ctx context.Context) error {
+	return t.e.run(ctx, t.clock, &t.meta, t.c.decorate)
+}
+
+// exec runs the function, with the chaos applied if enabled.
+func (t *task) exec(ctx context.Context) error {
+	if t.f != nil {
+		return t.f(ctx)
+	}
+
+	return t.call(ctx)
+}
+
+// run runs the function and reports its result. The condition of the
+// entry and, with WithSkipOnCancel, the context are checked right before
+// the function runs.
+func (t *task) run(ctx context.Context) error {
+	c, e := t.c, t.e
+
+	if e.cond != nil && !e.cond() {
+		c.emitSkipped([]Result{e.skip(ReasonCondition)})
+
+		return nil
+	}
+
+	if c.skipOnCancel && ctx.Err() != nil {
+		err := FuncError{
+			Name:     t.label(),
+			Index:    e.index,
+			Stage:    e.stage,
+			Category: CategorySkipped,
+			Critical: e.critical,
+			Err:      e.annotate(fmt.Errorf("%s: %w", ReasonContextDone, ctx.Err())),
+		}
+
+		res := Result{Name: t.label(), Status: StatusSkipped, Err: err, Reason: ReasonContextDone}
+		c.setResult(e, res)
+
+		c.emitSkipped([]Result{res})
+		c.notifyError(*e, res)
+		e.done(res)
+
+		return err
+	}
+
+	e.out.state.Store(outcomeRunning)
+
+	if c.observed() {
+		c.emit(Event{Type: EventFuncStarted, Name: t.label()})
+	}
+
+	start := t.clock.Now()
+	err := c.runLabeled(ctx, t)
+
+	res := Result{Status: StatusClosed, Duration: t.clock.Now().Sub(start)}
+	attempts := int(t.meta.attempts.Load())
+
+	// The name is built only if it is reported
+	if err != nil || c.verbose || c.observed() {
+		res.Name = t.label()
+	}
+
+	if err != nil {
+		category, stack := categorize(err)
+
+		// A named function prefixes its errors already
+		if category == CategoryTimedOut && e.name == "" {
+			err = fmt.Errorf("%s: %w", res.Name, err)
+		}
+
+		err = FuncError{
+			Name:     res.Name,
+			Index:    e.index,
+			Stage:    e.stage,
+			Category: category,
+			Duration: res.Duration,
+			Attempts: attempts,
+			Critical: e.critical,
+			Stack:    stack,
+			Err:      err,
+		}
+		res.Status = StatusFailed
+		res.Err = err
+	}
+
+	c.logResult(*e, res, attempts)
+	c.recordDuration(*e, res)
+	c.setResult(e, res)
+
+	c.emit(Event{Type: EventFuncFinished, Name: res.Name, Result: res})
+	c.notifyError(*e, res)
+	e.done(res)
+
+	return err
+}
+
+// notifyError passes the error of the function to the error hook and,
+// for a critical function, to the fatal hook, if any.
+func (c *Closer) notifyError(e entry, res Result) {
+	if res.Err == nil {
+		return
+	}
+
+	if c.onError != nil {
+		c.onError(res.Name, res.Err)
+	}
+
+	if c.onFatal != nil && e.critical {
+		c.onFatal(res.Name, res.Err)
+	}
+}
+
+// logResult logs the result of the function: every one in the verbose
+// mode, with its duration, outcome and, if it can retry, attempt count,
+// only the failed ones otherwise.
+func (c *Closer) logResult(e entry, res Result, attempts int) {
+	if !c.verbose {
+		if res.Err != nil {
+			c.log(LevelError, "closer: function failed", "name", res.Name, "error", res.Err)
+		}
+
+		return
+	}
+
+	level := LevelInfo
+	kv := []any{"name", res.Name, "duration", res.Duration, "status", res.Status.String()}
+
+	if e.retries > 0 {
+		kv = append(kv, "attempts", attempts)
+	}
+
+	if res.Err != nil {
+		level = LevelError
+		kv = append(kv, "error", res.Err)
+	}
+
+	c.log(level, "closer: function finished", kv...)
+}
+
+// emitSkipped emits the events of the skipped functions.
+func (c *Closer) emitSkipped(skipped []Result) {
+	for _, res := range skipped {
+		c.emit(Event{Type: EventFuncFinished, Name: res.Name, Result: res})
+	}
+}
+
+// runLabeled runs the task with the pprof labels naming the closer and
+// the function if enabled, so goroutine profiles taken during a hung
+// shutdown show which resource is stuck.
+func (c *Closer) runLabeled(ctx context.Context, t *task) error {
+	if !c.pprofLabels {
+		return t.exec(ctx)
+	}
+
+	labels := []string{"closer.func", t.label()}
+
+	if c.name != "" {
+		labels = append(labels, "closer", c.name)
+	}
+
+	var err error
+
+	pprof.Do(ctx, pprof.Labels(labels...), func(ctx context.Context) {
+		err = t.exec(ctx)
+	})
+
+	return err
+}
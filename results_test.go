@@ -0,0 +1,186 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Results_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	errFailed := errors.New("failed")
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("ok"))
+	cl.Add(func(ctx context.Context) error { return errFailed })
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("pending"))
+
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.ErrorIs(t, cl.CloseOne(context.Background()), errFailed)
+
+	results := cl.Results()
+
+	require.Len(t, results, 3)
+	require.Equal(t, "ok", results[0].Name)
+	require.Equal(t, StatusClosed, results[0].Status)
+	require.Equal(t, "#1", results[1].Name)
+	require.Equal(t, StatusFailed, results[1].Status)
+	require.ErrorIs(t, results[1].Err, errFailed)
+	require.Equal(t, StatusPending, results[2].Status)
+}
+
+func Test_Disable_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	mocks := []*mockCloseFunc{{}, {}, {}}
+
+	cl.Add(mocks[0].close, WithName("db"))
+	cl.Add(mocks[1].close, WithName("cache"))
+	cl.Add(mocks[2].close, WithName("cache"))
+
+	require.True(t, cl.Disable("cache"))
+	require.False(t, cl.Disable("unknown"))
+	require.Equal(t, []string{"db"}, cl.Plan())
+
+	require.NoError(t, cl.Close(context.Background()))
+
+	require.Equal(t, 1, mocks[0].calledCount)
+	require.Equal(t, 0, mocks[1].calledCount)
+	require.Equal(t, 0, mocks[2].calledCount)
+
+	results := cl.Results()
+
+	require.Equal(t, StatusClosed, results[0].Status)
+
+	for _, res := range results[1:] {
+		require.Equal(t, StatusSkipped, res.Status)
+		require.Equal(t, ReasonDisabled, res.Reason)
+	}
+
+	// The skipped functions are done with
+	require.False(t, cl.Enable("cache"))
+}
+
+func Test_Disable_EnablePath(t *testing.T) {
+	var cl Closer
+	mocks := []*mockCloseFunc{{}, {}, {}}
+
+	cl.Add(mocks[0].close, WithName("db"))
+	cl.Add(mocks[1].close, WithName("cache"))
+	cl.Add(mocks[2].close, WithName("queue"))
+
+	cl.Disable("db")
+	cl.Disable("cache")
+	require.True(t, cl.Enable("cache"))
+
+	// CloseOne skips over the disabled function
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.Equal(t, 0, mocks[0].calledCount)
+	require.Equal(t, 1, mocks[1].calledCount)
+
+	cl.Disable("queue")
+
+	require.ErrorContains(t, cl.CloseOne(context.Background()), ErrAllServicesClosed)
+	require.Equal(t, 0, mocks[2].calledCount)
+	require.Equal(t, StatusSkipped, cl.Results()[2].Status)
+}
+
+func Test_Condition_HappyPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+	mocks := []*mockCloseFunc{{}, {}}
+	dirty := false
+
+	cl.Add(mocks[0].close, WithName("cache"), WithCondition(func() bool { return dirty }))
+	cl.Add(mocks[1].close, WithName("db"), WithCondition(func() bool { return true }))
+
+	// The condition is checked at shutdown time
+	dirty = true
+
+	require.NoError(t, cl.CloseOne(context.Background()))
+	require.Equal(t, 1, mocks[0].calledCount)
+
+	dirty = false
+
+	cl.Add(mocks[0].close, WithName("cache"), WithCondition(func() bool { return dirty }))
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 1, mocks[0].calledCount)
+	require.Equal(t, 1, mocks[1].calledCount)
+
+	results := cl.Results()
+
+	require.Equal(t, StatusClosed, results[0].Status)
+	require.Equal(t, StatusClosed, results[1].Status)
+	require.Equal(t, StatusSkipped, results[2].Status)
+	require.Equal(t, ReasonCondition, results[2].Reason)
+}
+
+func Test_SkipOnCancel_HappyPath(t *testing.T) {
+	cl := New(WithSkipOnCancel(), WithSynchronousExecution())
+	mocks := []*mockCloseFunc{{}, {}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.Add(func(ctx context.Context) error {
+		cancel()
+		return nil
+	})
+	cl.Add(mocks[0].close, WithName("db"))
+	cl.Add(mocks[1].close)
+
+	err := cl.Close(ctx)
+
+	require.ErrorContains(t, err, "db: "+ReasonContextDone)
+	require.Equal(t, 0, mocks[0].calledCount)
+	require.Equal(t, 0, mocks[1].calledCount)
+
+	results := cl.Results()
+
+	require.Equal(t, StatusClosed, results[0].Status)
+
+	for _, res := range results[1:] {
+		require.Equal(t, StatusSkipped, res.Status)
+		require.Equal(t, ReasonContextDone, res.Reason)
+		require.ErrorIs(t, res.Err, context.Canceled)
+	}
+}
+
+func Test_PprofLabels_HappyPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		labels map[string]string
+	}{
+		{name: "disabled", labels: map[string]string{}},
+		{
+			name:   "func",
+			opts:   []Option{WithPprofLabels()},
+			labels: map[string]string{"closer.func": "db"},
+		},
+		{
+			name:   "closer",
+			opts:   []Option{WithPprofLabels(), WithCloserName("app")},
+			labels: map[string]string{"closer.func": "db", "closer": "app"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cl := New(test.opts...)
+			labels := map[string]string{}
+
+			cl.Add(func(ctx context.Context) error {
+				pprof.ForLabels(ctx, func(key, value string) bool {
+					labels[key] = value
+					return true
+				})
+
+				return nil
+			}, WithName("db"))
+
+			require.NoError(t, cl.Close(context.Background()))
+			require.Equal(t, test.labels, labels)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Scope returns a child Closer configured with opts for a per-request or
+// per-job resource scope. A scope that is closed successfully by Close,
+// with nothing left to close, detaches from its parent; the scopes still
+// alive are swept by a Close of the parent, before its own functions.
+// CloseExcept, CloseUntil and ReloadByTag of the parent leave the scopes
+// alone. The scopes are not part of the Plan, Size or Results of the
+// parent.
+func (c *Closer) Scope(opts ...Option) *Closer {
+	child := New(opts...)
+	child.parent = c
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.scopes == nil {
+		c.scopes = make(map[*Closer]struct{})
+	}
+
+	c.scopes[child] = struct{}{}
+
+	return child
+}
+
+// detach removes the scope from its parent, if any.
+func (c *Closer) detach() {
+	if c.parent == nil {
+		return
+	}
+
+	c.parent.mu.Lock()
+	defer c.parent.mu.Unlock()
+
+	delete(c.parent.scopes, c)
+}
+
+// scopeFuncs returns the functions closing the live scopes.
+func scopeFuncs(scopes map[*Closer]struct{}) []Func {
+	funcs := make([]Func, 0, len(scopes))
+
+	for scope := range scopes {
+		funcs = append(funcs, func(ctx context.Context) error {
+			if scope.Remaining() == 0 {
+				return nil
+			}
+
+			if err := scope.Close(ctx); err != nil {
+				return fmt.Errorf("scope: %w", err)
+			}
+
+			return nil
+		})
+	}
+
+	return funcs
+}
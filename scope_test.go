@@ -0,0 +1,84 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Scope_HappyPath(t *testing.T) {
+	cl := New()
+	mocks := []*mockCloseFunc{{}, {}}
+
+	scope := cl.Scope()
+	scope.Add(mocks[0].close)
+
+	require.NoError(t, scope.Close(context.Background()))
+	require.Empty(t, cl.scopes)
+
+	// The parent has nothing to sweep
+	cl.Add(mocks[1].close)
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, 1, mocks[0].calledCount)
+	require.Equal(t, 1, mocks[1].calledCount)
+}
+
+func Test_Scope_SweepPath(t *testing.T) {
+	cl := New(WithSynchronousExecution())
+
+	var order []string
+
+	add := func(c *Closer, name string) {
+		c.Add(func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	add(cl, "db")
+
+	scope := cl.Scope(WithSynchronousExecution())
+	add(scope, "request")
+
+	// The scopes are swept before the functions of the parent
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []string{"request", "db"}, order)
+	require.Equal(t, 0, scope.Remaining())
+
+	// A scope alone is swept as well
+	scope = cl.Scope()
+	add(scope, "job")
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []string{"request", "db", "job"}, order)
+}
+
+func Test_Scope_ErrorPath(t *testing.T) {
+	cl := New()
+
+	scope := cl.Scope()
+	scope.Add(func(ctx context.Context) error {
+		return errors.New("busy")
+	})
+
+	other := cl.Scope()
+	other.Add(func(ctx context.Context) error {
+		return errors.New("leak")
+	})
+
+	// A failed scope stays attached, but has nothing left to close
+	require.Error(t, scope.Close(context.Background()))
+	require.Len(t, cl.scopes, 2)
+
+	require.EqualError(t, cl.Close(context.Background()), "closer.Close: scope: closer.Close: leak")
+
+	// CloseExcept leaves the scopes alone
+	cl.Scope().Add(func(ctx context.Context) error { return nil })
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("db"))
+
+	require.NoError(t, cl.CloseExcept(context.Background(), "none"))
+	require.Len(t, cl.scopes, 1)
+}
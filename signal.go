@@ -0,0 +1,101 @@
+package closer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// SignalAction is an action run by RouteSignals when a signal arrives.
+type SignalAction func(ctx context.Context, sig os.Signal)
+
+// RouteSignals runs the action routed to a signal every time it arrives,
+// each on its own goroutine, until ctx is done or the returned stop
+// function is called. It allows e.g. SIGTERM to close everything,
+// SIGHUP to reload a subset of the resources and SIGUSR1 to dump the
+// state. The signals routed to a nil action are not listened to; with no
+// action at all, nothing is and stop does nothing:
+//
+//	stop := cl.RouteSignals(ctx, map[os.Signal]closer.SignalAction{
+//		syscall.SIGTERM: cl.CloseAction(),
+//		syscall.SIGHUP:  cl.ReloadAction("config", reopen),
+//		syscall.SIGUSR1: cl.DumpAction(os.Stderr),
+//	})
+func (c *Closer) RouteSignals(ctx context.Context, routes map[os.Signal]SignalAction) (stop func()) {
+	// The map is copied, a later change of routes doesn't race the router
+	actions := make(map[os.Signal]SignalAction, len(routes))
+	sigs := make([]os.Signal, 0, len(routes))
+
+	for sig, action := range routes {
+		if action == nil {
+			continue
+		}
+
+		actions[sig] = action
+		sigs = append(sigs, sig)
+	}
+
+	// signal.Notify without signals would relay them all
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	ch := make(chan os.Signal, len(sigs))
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-ch:
+				go actions[sig](ctx, sig)
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// CloseAction returns the action running Close with a context keeping the
+// values of the routing context but not cancelled with it. The default
+// behavior of the signal is restored first, so a second signal terminates
+// the process while closing. The error of Close is logged.
+func (c *Closer) CloseAction() SignalAction {
+	return func(ctx context.Context, sig os.Signal) {
+		signal.Reset(sig)
+
+		if err := c.Close(context.WithoutCancel(ctx)); err != nil {
+			c.log(LevelError, "closer: close on signal failed", "signal", sig.String(), "error", err)
+		}
+	}
+}
+
+// ReloadAction returns the action running ReloadByTag with the tag and
+// reopen. The error of the reload is logged.
+func (c *Closer) ReloadAction(tag string, reopen func(ctx context.Context) error) SignalAction {
+	return func(ctx context.Context, sig os.Signal) {
+		if err := c.ReloadByTag(ctx, tag, reopen); err != nil {
+			c.log(LevelError, "closer: reload failed", "signal", sig.String(), "tag", tag, "error", err)
+		}
+	}
+}
+
+// DumpAction returns the action writing the shutdown plan and the state
+// of the Closer to w, one line per signal.
+func (c *Closer) DumpAction(w io.Writer) SignalAction {
+	return func(ctx context.Context, sig os.Signal) {
+		state := c.expvarState().(map[string]any)
+
+		_, _ = fmt.Fprintf(w, "closer: registered=%d remaining=%d closing=%t last_error=%q plan=[%s]\n",
+			state["registered"], state["remaining"], state["closing"], state["last_error"],
+			strings.Join(c.Plan(), " "))
+	}
+}
@@ -0,0 +1,130 @@
+package closer
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// chanWriter sends every write to a channel.
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func Test_RouteSignals_HappyPath(t *testing.T) {
+	cl := New()
+	dumps := make(chanWriter, 1)
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("db"))
+
+	stop := cl.RouteSignals(context.Background(), map[os.Signal]SignalAction{
+		syscall.SIGUSR1: cl.DumpAction(dumps),
+		syscall.SIGUSR2: cl.CloseAction(),
+	})
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case dump := <-dumps:
+		require.Equal(t, "closer: registered=1 remaining=1 closing=false last_error=\"\" plan=[db]\n", dump)
+	case <-time.After(time.Second):
+		t.Fatal("no dump on SIGUSR1")
+	}
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, cl.WaitClosed(ctx))
+	require.Equal(t, 0, cl.Remaining())
+}
+
+func Test_RouteSignals_CancelWithCtxPath(t *testing.T) {
+	cl := New()
+	dumps := make(chanWriter, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cl.RouteSignals(ctx, map[os.Signal]SignalAction{
+		syscall.SIGUSR1: cl.DumpAction(dumps),
+	})
+
+	// Keep the process alive after the router stops listening
+	keep := cl.RouteSignals(context.Background(), map[os.Signal]SignalAction{
+		syscall.SIGUSR1: func(ctx context.Context, sig os.Signal) {},
+	})
+	defer keep()
+
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-dumps:
+		t.Fatal("dump after the router stopped")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func Test_RouteSignals_NoRoutesPath(t *testing.T) {
+	cl := New()
+	received := make(chan struct{}, 1)
+
+	// Keep the process alive and tell when SIGUSR1 was relayed
+	keep := cl.RouteSignals(context.Background(), map[os.Signal]SignalAction{
+		syscall.SIGUSR1: func(ctx context.Context, sig os.Signal) { received <- struct{}{} },
+	})
+	defer keep()
+
+	for _, routes := range []map[os.Signal]SignalAction{nil, {}, {syscall.SIGUSR1: nil}} {
+		stop := cl.RouteSignals(context.Background(), routes)
+		defer stop()
+	}
+
+	// No router without actions listens to the signal, none crashes on it
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("SIGUSR1 not relayed")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+}
+
+func Test_RouteSignals_NilActionPath(t *testing.T) {
+	cl := New()
+	dumps := make(chanWriter, 1)
+
+	stop := cl.RouteSignals(context.Background(), map[os.Signal]SignalAction{
+		syscall.SIGUSR1: cl.DumpAction(dumps),
+		syscall.SIGUSR2: nil,
+	})
+	defer stop()
+
+	// Keep the process alive: SIGUSR2 is not listened to by the router
+	keep := cl.RouteSignals(context.Background(), map[os.Signal]SignalAction{
+		syscall.SIGUSR2: func(ctx context.Context, sig os.Signal) {},
+	})
+	defer keep()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-dumps:
+	case <-time.After(time.Second):
+		t.Fatal("no dump on SIGUSR1")
+	}
+}
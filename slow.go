@@ -0,0 +1,54 @@
+package closer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Slowest returns the results of the n slowest functions that ran,
+// the slowest first, to guide the optimization of the shutdown. None
+// is returned for a negative n.
+func (c *Closer) Slowest(n int) []Result {
+	var ran []Result
+
+	for _, res := range c.Results() {
+		if res.Status == StatusClosed || res.Status == StatusFailed {
+			ran = append(ran, res)
+		}
+	}
+
+	// The registration order is kept for the same duration
+	slices.SortStableFunc(ran, func(a, b Result) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+
+	return ran[:min(max(n, 0), len(ran))]
+}
+
+// logSlowest logs the slowest functions once Close finished, if enabled,
+// with the fraction of the budget each consumed: the time from start to
+// the deadline of ctx or, without a deadline, the duration of Close.
+func (c *Closer) logSlowest(ctx context.Context, op string, start time.Time) {
+	if c.slowReport <= 0 || c.logger == nil {
+		return
+	}
+
+	budget := c.getClock().Now().Sub(start)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		budget = deadline.Sub(start)
+	}
+
+	for i, res := range c.Slowest(c.slowReport) {
+		share := "n/a"
+
+		if budget > 0 {
+			share = fmt.Sprintf("%.1f%%", float64(res.Duration)/float64(budget)*100)
+		}
+
+		c.log(LevelInfo, "closer: slow function", "op", op, "rank", i+1, "name", res.Name, "duration", res.Duration, "budget_share", share)
+	}
+}
@@ -0,0 +1,88 @@
+package closer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stepClock is a clock advanced by the test only, its timers are real.
+type stepClock struct {
+	realClock
+
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *stepClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func Test_Slowest_HappyPath(t *testing.T) {
+	clock := &stepClock{now: time.Now()}
+	cl := New(WithClock(clock), WithSynchronousExecution())
+
+	// Every function takes its duration on the clock, whatever the load
+	for i, d := range []time.Duration{10, 30, 0, 20} {
+		cl.Add(func(ctx context.Context) error {
+			clock.advance(d * time.Millisecond)
+			return nil
+		}, WithName(string(rune('a'+i))))
+	}
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("disabled"))
+	cl.Disable("disabled")
+
+	require.Empty(t, cl.Slowest(2))
+	require.NoError(t, cl.Close(context.Background()))
+
+	slowest := cl.Slowest(2)
+
+	require.Len(t, slowest, 2)
+	require.Equal(t, "b", slowest[0].Name)
+	require.Equal(t, "d", slowest[1].Name)
+	require.Len(t, cl.Slowest(10), 4)
+	require.Empty(t, cl.Slowest(0))
+	require.Empty(t, cl.Slowest(-1))
+}
+
+func Test_SlowReport_HappyPath(t *testing.T) {
+	var records []record
+
+	logger := LoggerFunc(func(level Level, msg string, kv ...any) {
+		records = append(records, record{level: level, msg: msg, kv: kv})
+	})
+
+	cl := New(WithLogger(logger), WithSlowReport(1), WithSynchronousExecution())
+
+	cl.Add(func(ctx context.Context) error { return nil }, WithName("fast"))
+	cl.Add(func(ctx context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}, WithName("slow"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, cl.Close(ctx))
+	require.Len(t, records, 3)
+
+	rec := records[1]
+
+	require.Equal(t, "closer: slow function", rec.msg)
+	require.Equal(t, []any{"op", "closer.Close", "rank", 1, "name", "slow"}, rec.kv[:6])
+	require.Regexp(t, `^[0-9]+\.[0-9]%$`, rec.kv[9])
+}
@@ -0,0 +1,106 @@
+package closer
+
+// StageBuilder adds functions to a named stage, so shutdown pipelines
+// read in order at the wiring site:
+//
+//	cl.Stage("traffic").Add(srv.Shutdown).
+//		Then("workers").Add(pool.Stop).
+//		Then("storage").Add(db.Close)
+type StageBuilder struct {
+	c  *Closer
+	id int // Stage of the functions
+}
+
+// Stage returns the builder adding functions to the named stage. A new
+// name gets the stage after the last named one, starting from 0, so the
+// named stages run in the order they are first mentioned; being plain
+// stages, they share the numbers with AddStage and WithStage.
+func (c *Closer) Stage(name string) *StageBuilder {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &StageBuilder{c: c, id: c.stageID(name, false)}
+}
+
+// stageID returns the stage of the name, numbering a new one after the
+// named stages, or before them if reverse. It must be called under the
+// mutex.
+func (c *Closer) stageID(name string, reverse bool) int {
+	if id, ok := c.stageNames[name]; ok {
+		return id
+	}
+
+	id := 0
+
+	for _, other := range c.stageNames {
+		if reverse {
+			id = min(id, other-1)
+		} else {
+			id = max(id, other+1)
+		}
+	}
+
+	if c.stageNames == nil {
+		c.stageNames = make(map[string]int)
+	}
+
+	c.stageNames[name] = id
+
+	return id
+}
+
+// stageName returns the name of the stage, empty if it has none.
+// It must be called under the mutex.
+func (c *Closer) stageName(id int) string {
+	for name, other := range c.stageNames {
+		if other == id {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Add adds a function to the stage like Closer.AddStage.
+func (b *StageBuilder) Add(f Func, opts ...FuncOption) *StageBuilder {
+	mustAdd(b.c.add("closer.StageBuilder.Add", f, append(opts, WithStage(b.id))))
+
+	return b
+}
+
+// Then returns the builder of the named stage, which runs after the
+// current one if the name is new.
+func (b *StageBuilder) Then(name string) *StageBuilder {
+	return b.c.Stage(name)
+}
+
+// Group is a registration facade for a group of functions closed
+// concurrently, a simpler model than numbered stages: the groups are
+// closed one after another in the order they were created, or in the
+// reverse order with WithReverseGroups, so the groups of the resources
+// created first are closed last.
+type Group struct {
+	c    *Closer
+	name string
+	id   int // Stage of the group
+}
+
+// Group returns the group with the name, creating it on the first call.
+// A group is a named stage (see Stage): the functions added with
+// AddStage to its number belong to it as well.
+func (c *Closer) Group(name string) *Group {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &Group{c: c, name: name, id: c.stageID(name, c.reverseGroups)}
+}
+
+// Name returns the name of the group.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Add adds a function to the group like Closer.Add.
+func (g *Group) Add(f Func, opts ...FuncOption) Handle {
+	return mustAdd(g.c.add("closer.Group.Add", f, append(opts, WithStage(g.id))))
+}
@@ -0,0 +1,87 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Stage_HappyPath(t *testing.T) {
+	var (
+		cl    Closer
+		mu    sync.Mutex
+		order []string
+	)
+
+	record := func(name string) Func {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			order = append(order, name)
+
+			return nil
+		}
+	}
+
+	cl.Stage("traffic").Add(record("http")).
+		Then("workers").Add(record("pool")).
+		Then("storage").Add(record("db"))
+
+	// Reopening a stage adds to it rather than numbering a new one
+	cl.Stage("workers").Add(record("queue"), WithPriority(-1))
+
+	var buf bytes.Buffer
+
+	require.NoError(t, cl.Dump(&buf))
+	require.Contains(t, buf.String(), "stage 1 (workers):")
+
+	require.NoError(t, cl.Close(context.Background()))
+	require.Equal(t, []string{"http", "pool", "queue", "db"}, order)
+}
+
+func Test_Group_HappyPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{name: "creation_order", expected: []string{"db", "http", "http"}},
+		{name: "reverse_order", opts: []Option{WithReverseGroups()}, expected: []string{"http", "http", "db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				cl    = New(tt.opts...)
+				mu    sync.Mutex
+				order []string
+			)
+
+			record := func(name string) Func {
+				return func(ctx context.Context) error {
+					mu.Lock()
+					defer mu.Unlock()
+
+					order = append(order, name)
+
+					return nil
+				}
+			}
+
+			storage := cl.Group("storage")
+			traffic := cl.Group("traffic")
+
+			storage.Add(record("db"))
+			traffic.Add(record("http"))
+			cl.Group("traffic").Add(record("http"))
+
+			require.Equal(t, "traffic", traffic.Name())
+			require.NoError(t, cl.Close(context.Background()))
+			require.Equal(t, tt.expected, order)
+		})
+	}
+}
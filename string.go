@@ -0,0 +1,118 @@
+package closer
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// String returns the dump of the Closer, see Dump.
+func (c *Closer) String() string {
+	var b strings.Builder
+
+	_ = c.Dump(&b)
+
+	return b.String()
+}
+
+// Dump writes the registered functions to w in the closing order, stage
+// by stage, with their names, tags, states and call sites, so complex
+// shutdown configurations can be inspected at runtime or in bug reports.
+// A function is pending until it is taken for closing, closing until it
+// returns, then closed, failed or skipped.
+func (c *Closer) Dump(w io.Writer) error {
+	c.mu.Lock()
+
+	var (
+		stages  = splitStages(slices.Clone(c.funcs))
+		results = c.collectResults()
+		closing = c.closing > 0
+		names   = make([]string, len(stages))
+	)
+
+	for i, st := range stages {
+		names[i] = c.stageName(st.id)
+	}
+
+	c.mu.Unlock()
+
+	var b strings.Builder
+
+	name := "closer"
+
+	if c.name != "" {
+		name = fmt.Sprintf("closer %q", c.name)
+	}
+
+	fmt.Fprintf(&b, "%s: %d registered, %d remaining", name, c.Size(), c.Remaining())
+
+	if closing {
+		b.WriteString(", closing")
+	}
+
+	b.WriteString("\n")
+
+	for i, st := range stages {
+		if names[i] != "" {
+			fmt.Fprintf(&b, "stage %d (%s):\n", st.id, names[i])
+		} else {
+			fmt.Fprintf(&b, "stage %d:\n", st.id)
+		}
+
+		for _, e := range st.entries {
+			fmt.Fprintf(&b, "  %s: %s", e.label(), entryState(e, results[e.index]))
+
+			if e.priority != 0 {
+				fmt.Fprintf(&b, ", priority %d", e.priority)
+			}
+
+			if len(e.tags) > 0 {
+				fmt.Fprintf(&b, ", tags %s", strings.Join(e.tags, ","))
+			}
+
+			if site := e.callSite(); site != "" {
+				fmt.Fprintf(&b, ", registered at %s", site)
+			}
+
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// entryState describes the state of the entry with the result.
+func entryState(e entry, res Result) string {
+	switch {
+	case !e.taken && e.disabled:
+		return "disabled"
+	case !e.taken:
+		return "pending"
+	case res.Status == StatusPending:
+		return "closing"
+	case res.Err != nil:
+		return fmt.Sprintf("%s (%v)", res.Status, res.Err)
+	default:
+		return res.Status.String()
+	}
+}
+
+// callSite returns the file and line the entry was registered at,
+// empty if unknown.
+func (e entry) callSite() string {
+	if e.site == 0 {
+		return ""
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{e.site}).Next()
+
+	if frame.File == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
@@ -0,0 +1,59 @@
+package closer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Dump_HappyPath(t *testing.T) {
+	cl := New(WithCloserName("app"))
+	nop := func(ctx context.Context) error { return nil }
+
+	site := lineAfter(t, 1)
+	cl.Add(func(ctx context.Context) error { return errors.New("busy") }, WithName("db"), WithTags("storage", "sql"))
+	cl.AddStage(1, nop, WithName("logger"))
+	cl.Add(nop, WithName("cache"), WithPriority(5))
+	cl.Add(nop, WithName("metrics"))
+	cl.Disable("metrics")
+
+	require.Error(t, cl.CloseExcept(context.Background(), "logger"))
+
+	cl.Add(nop)
+
+	var buf bytes.Buffer
+
+	require.NoError(t, cl.Dump(&buf))
+
+	var lines []string
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		line, _, _ = strings.Cut(line, ", registered at ")
+		lines = append(lines, line)
+	}
+
+	require.Equal(t, []string{
+		`closer "app": 5 registered, 2 remaining`,
+		`stage 0:`,
+		`  cache: closed, priority 5`,
+		`  db: failed (db: busy), tags storage,sql`,
+		`  metrics: skipped`,
+		`  #4: pending`,
+		`stage 1:`,
+		`  logger: pending`,
+	}, lines)
+
+	require.Contains(t, buf.String(), "sql, registered at "+site+"\n")
+
+	require.Equal(t, buf.String(), cl.String())
+}
+
+func Test_Dump_ZeroPath(t *testing.T) {
+	var cl Closer
+
+	require.Equal(t, "closer: 0 registered, 0 remaining\n", cl.String())
+}
@@ -0,0 +1,141 @@
+package closer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Supervisor runs the long-lived components of a service: it restarts
+// the crashed ones during normal operation and uses its Closer to tear
+// them all down on shutdown.
+type Supervisor struct {
+	cl      *Closer
+	backoff time.Duration
+
+	mu         sync.Mutex
+	components []*component
+	ctx        context.Context // Context of the run, nil if not running
+	wg         sync.WaitGroup
+}
+
+// component is a Start/Stop pair run by a Supervisor.
+type component struct {
+	name     string
+	start    Func
+	stop     Func
+	restarts int
+}
+
+// NewSupervisor returns a Supervisor tearing the components down with cl
+// and waiting for backoff before restarting a crashed component.
+// The Closer can hold other functions as well.
+func NewSupervisor(cl *Closer, backoff time.Duration) *Supervisor {
+	return &Supervisor{cl: cl, backoff: backoff}
+}
+
+// Add adds the component to the Supervisor, it is started at once if the
+// Supervisor is running. start runs the component until its context is
+// done, it crashes when it returns an error earlier: then stop is called
+// to release what start acquired and the component is restarted.
+// A component returning nil is done and is not restarted.
+// stop is also added to the Closer under the name, so it runs on shutdown;
+// it must tolerate being called after the component crashed.
+func (s *Supervisor) Add(name string, start, stop Func) {
+	comp := &component{name: name, start: start, stop: stop}
+
+	s.cl.Add(stop, WithName(name))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.components = append(s.components, comp)
+
+	if s.ctx != nil {
+		s.launch(comp)
+	}
+}
+
+// Run starts the components and supervises them until ctx is done, then it
+// closes the Closer with a context keeping the values of ctx but not
+// cancelled with it, waits for the components to return and returns the
+// error of Close. The context passed to start is done before the shutdown.
+func (s *Supervisor) Run(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s.mu.Lock()
+
+	s.ctx = runCtx
+
+	for _, comp := range s.components {
+		s.launch(comp)
+	}
+
+	s.mu.Unlock()
+
+	<-runCtx.Done()
+
+	s.mu.Lock()
+	s.ctx = nil
+	s.mu.Unlock()
+
+	err := s.cl.Close(context.WithoutCancel(ctx))
+
+	s.wg.Wait()
+
+	return err
+}
+
+// Restarts returns the number of times the component with the name
+// was restarted.
+func (s *Supervisor) Restarts(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restarts := 0
+
+	for _, comp := range s.components {
+		if comp.name == name {
+			restarts += comp.restarts
+		}
+	}
+
+	return restarts
+}
+
+// launch runs the component, restarting it until the run is over.
+// It must be called under the mutex.
+func (s *Supervisor) launch(comp *component) {
+	ctx := s.ctx
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		for {
+			err := comp.start(ctx)
+
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+
+			s.cl.log(LevelWarn, "closer: component crashed", "name", comp.name, "error", err)
+
+			if err := comp.stop(ctx); err != nil {
+				s.cl.log(LevelError, "closer: component stop failed", "name", comp.name, "error", err)
+			}
+
+			sleep(ctx, s.cl.getClock(), s.backoff)
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.mu.Lock()
+			comp.restarts++
+			s.mu.Unlock()
+		}
+	}()
+}
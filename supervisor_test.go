@@ -0,0 +1,92 @@
+package closer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Supervisor_HappyPath(t *testing.T) {
+	var (
+		starts  atomic.Int32
+		stops   atomic.Int32
+		running = make(chan struct{})
+	)
+
+	s := NewSupervisor(New(), time.Millisecond)
+
+	s.Add("worker", func(ctx context.Context) error {
+		// Crash twice, then run until the shutdown
+		if starts.Add(1) < 3 {
+			return errors.New("crashed")
+		}
+
+		close(running)
+		<-ctx.Done()
+
+		return nil
+	}, func(ctx context.Context) error {
+		stops.Add(1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-running
+		cancel()
+	}()
+
+	require.NoError(t, s.Run(ctx))
+	require.Equal(t, int32(3), starts.Load())
+	require.Equal(t, int32(3), stops.Load())
+	require.Equal(t, 2, s.Restarts("worker"))
+}
+
+func Test_Supervisor_DonePath(t *testing.T) {
+	var starts atomic.Int32
+
+	cl := New()
+	s := NewSupervisor(cl, time.Millisecond)
+
+	s.Add("job", func(ctx context.Context) error {
+		starts.Add(1)
+		return nil
+	}, func(ctx context.Context) error {
+		return errors.New("stop failed")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := s.Run(ctx)
+
+	require.EqualError(t, err, "closer.Close: job: stop failed")
+	require.Equal(t, int32(1), starts.Load())
+	require.Equal(t, 0, s.Restarts("job"))
+	require.Equal(t, 0, cl.Remaining())
+}
+
+func Test_Supervisor_CancelWithCtxPath(t *testing.T) {
+	var starts atomic.Int32
+
+	s := NewSupervisor(New(), time.Hour)
+
+	s.Add("worker", func(ctx context.Context) error {
+		starts.Add(1)
+		return errors.New("crashed")
+	}, func(ctx context.Context) error {
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// The backoff is cut short by the shutdown
+	require.NoError(t, s.Run(ctx))
+	require.Equal(t, int32(1), starts.Load())
+}